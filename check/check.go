@@ -0,0 +1,165 @@
+// Package check verifies the files of a database are intact.  Each check walks
+// part of the on-disk state and reports every Problem it finds rather than
+// stopping at the first one so a single run gives the full picture.
+package check
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spencercdixon/rql/storage"
+)
+
+// Problem is a single piece of corruption found while checking.
+type Problem struct {
+	// Block is where the problem was found.  BlockNum is -1 for problems with
+	// the file as a whole.
+	Block   *storage.Block
+	Message string
+}
+
+// String lets us print problems: [file users.tbl, block 3] something is wrong
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s", p.Block, p.Message)
+}
+
+// Database runs every check against the files of a database.
+func Database(fm *storage.FileManager) ([]Problem, error) {
+	files, err := dataFiles(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, name := range files {
+		found, err := File(fm, name)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+
+		found, err = Pages(fm, name)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+
+		if !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		found, err = Log(fm, name)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// File checks that filename is made up of whole blocks.  A partial block at
+// the end of a file is left behind by a torn write.
+func File(fm *storage.FileManager, filename string) ([]Problem, error) {
+	info, err := fm.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if extra := info.Size() % int64(fm.BlockSize); extra != 0 {
+		return []Problem{{
+			Block:   storage.NewBlock(filename, -1),
+			Message: fmt.Sprintf("file ends with a partial block of %d bytes", extra),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// Pages reads every block of filename and reports those whose contents do not
+// match the checksum in their page header.
+func Pages(fm *storage.FileManager, filename string) ([]Problem, error) {
+	size, err := fm.Size(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	p := storage.NewPage(fm)
+	for blkNum := 0; blkNum < size; blkNum++ {
+		blk := storage.NewBlock(filename, blkNum)
+		err := p.Read(blk)
+		if corrupt, ok := err.(*storage.CorruptPageError); ok {
+			// the block is already part of the problem so only give the
+			// checksums rather than the error's full message
+			problems = append(problems, Problem{
+				Block:   blk,
+				Message: fmt.Sprintf("checksum mismatch: stored %08x, computed %08x", corrupt.Stored, corrupt.Computed),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// Log follows the chain of back pointers written by the LogManager in every
+// block of the log.  Each pointer must land inside the block and point further
+// towards the front of it than the one before so the chain ends at zero.
+// Blocks that fail their checksum are left for Pages to report.
+func Log(fm *storage.FileManager, filename string) ([]Problem, error) {
+	size, err := fm.Size(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	p := storage.NewPage(fm)
+	for blkNum := 0; blkNum < size; blkNum++ {
+		blk := storage.NewBlock(filename, blkNum)
+		err := p.Read(blk)
+		if _, ok := err.(*storage.CorruptPageError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the first int of a log block points at the last record
+		prev := p.Size()
+		pos, err := p.GetInt(0)
+		for err == nil && pos != 0 {
+			if pos < storage.IntSize || pos > prev-storage.IntSize {
+				problems = append(problems, Problem{
+					Block:   blk,
+					Message: fmt.Sprintf("broken log chain: record pointer %d after %d", pos, prev),
+				})
+				break
+			}
+			prev = pos
+			pos, err = p.GetInt(pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// dataFiles lists the files storing blocks for the database.  The header and
+// hidden files, like the REPL history, are not made of blocks and are skipped.
+func dataFiles(fm *storage.FileManager) ([]string, error) {
+	infos, err := ioutil.ReadDir(fm.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, info := range infos {
+		if info.IsDir() || info.Name() == storage.HeaderFile || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		files = append(files, info.Name())
+	}
+	return files, nil
+}
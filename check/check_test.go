@@ -0,0 +1,89 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestHealthyDatabase(t *testing.T) {
+	defer cleanUp("healthy")
+	fm := newLog(t, "healthy", 100)
+
+	problems, err := Database(fm)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(problems))
+}
+
+func TestBrokenLogChain(t *testing.T) {
+	defer cleanUp("brokenchain")
+	fm := newLog(t, "brokenchain", 3)
+
+	// point the last record somewhere past the end of the block
+	blk := storage.NewBlock("brokenchain.log", 0)
+	p := storage.NewPage(fm)
+	p.Read(blk)
+	p.SetInt(0, p.Size())
+	p.Write(blk)
+
+	problems, err := Log(fm, "brokenchain.log")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(problems))
+	testutil.Assert(t, problems[0].Block.Equals(blk), "problem names the block")
+}
+
+func TestPartialBlock(t *testing.T) {
+	defer cleanUp("partial")
+	fm := newLog(t, "partial", 3)
+
+	f, err := os.OpenFile(filepath.Join(fm.Dir, "partial.log"), os.O_APPEND|os.O_WRONLY, 0777)
+	testutil.Ok(t, err)
+	f.Write([]byte("torn"))
+	f.Close()
+
+	problems, err := File(fm, "partial.log")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(problems))
+	testutil.Equals(t, "[file partial.log, block -1] file ends with a partial block of 4 bytes", problems[0].String())
+}
+
+func TestCorruptPage(t *testing.T) {
+	defer cleanUp("corruptpage")
+	fm := newLog(t, "corruptpage", 3)
+
+	f, err := os.OpenFile(filepath.Join(fm.Dir, "corruptpage.log"), os.O_WRONLY, 0777)
+	testutil.Ok(t, err)
+	f.WriteAt([]byte("rot"), 100)
+	f.Close()
+
+	problems, err := Database(fm)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(problems))
+	testutil.Assert(t, problems[0].Block.Equals(storage.NewBlock("corruptpage.log", 0)), "problem names the block")
+	testutil.Equals(t, 1, strings.Count(problems[0].String(), "corruptpage.log"))
+}
+
+func newLog(t *testing.T, dbName string, records int) *storage.FileManager {
+	t.Helper()
+	fm, err := storage.NewFileManager(dbName)
+	testutil.Ok(t, err)
+	lm, err := storage.NewLogManager(dbName+".log", fm)
+	testutil.Ok(t, err)
+	for i := 0; i < records; i++ {
+		lm.Append([]interface{}{"record", i})
+	}
+	lm.Flush()
+	return fm
+}
+
+// remove the db directories and files that get created while testing
+func cleanUp(dbName string) {
+	home, _ := homedir.Dir()
+	path := filepath.Join(home, "rql", dbName)
+	os.RemoveAll(path)
+}
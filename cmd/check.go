@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spencercdixon/rql/check"
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd verifies a database's files and exits non-zero if any are corrupt
+var checkCmd = &cobra.Command{
+	Use:          "check DB",
+	Short:        "Check a database's files for corruption",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := storage.Dir(args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(dir); err != nil {
+			return err
+		}
+
+		fm, err := storage.NewFileManager(args[0])
+		if err != nil {
+			return err
+		}
+
+		problems, err := check.Database(fm)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s: %d problem(s) found", args[0], len(problems))
+		}
+
+		fmt.Printf("%s: ok\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(checkCmd)
+}
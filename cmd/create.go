@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spf13/cobra"
+)
+
+// createBlockSize is the block size for the new database
+var createBlockSize int
+
+// createCmd creates a new database with settings that can't be changed later
+var createCmd = &cobra.Command{
+	Use:          "create DB",
+	Short:        "Create a new database",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := storage.Dir(args[0])
+		if err != nil {
+			return err
+		}
+		exists, err := storage.Exists(args[0])
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("database %s already exists in %s", args[0], dir)
+		}
+
+		fm, err := storage.OpenFileManager(args[0], storage.Options{BlockSize: createBlockSize})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("created %s with a block size of %d bytes\n", fm.Dir, fm.BlockSize)
+		return nil
+	},
+}
+
+func init() {
+	createCmd.Flags().IntVar(
+		&createBlockSize,
+		"block-size",
+		storage.DefaultBlockSize,
+		fmt.Sprintf("bytes per block (%d-%d), e.g. 4096 or 8192", storage.MinBlockSize, storage.MaxBlockSize),
+	)
+	RootCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spencercdixon/rql/inspect"
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spf13/cobra"
+)
+
+// inspectJSON prints inspection output as JSON instead of text
+var inspectJSON bool
+
+// inspectLogFile overrides the name of the log file to walk
+var inspectLogFile string
+
+// inspectCmd groups the commands for looking at a database's files on disk
+var inspectCmd = &cobra.Command{
+	Use:          "inspect",
+	Short:        "Dump the blocks and log records stored on disk",
+	SilenceUsage: true,
+}
+
+var inspectBlockCmd = &cobra.Command{
+	Use:          "block DB FILE BLOCKNUM",
+	Short:        "Dump a block of a file as hex, ints and strings",
+	Args:         cobra.ExactArgs(3),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blkNum, err := strconv.Atoi(args[2])
+		if err != nil {
+			return errors.Wrap(err, "parsing block number")
+		}
+
+		fm, err := openExisting(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		size, err := fm.Size(args[1])
+		if err != nil {
+			return err
+		}
+		if blkNum < 0 || blkNum >= size {
+			return fmt.Errorf("block %d out of range, %s has %d blocks", blkNum, args[1], size)
+		}
+
+		dump := inspect.Block(fm, storage.NewBlock(args[1], blkNum))
+		if inspectJSON {
+			return printJSON(dump)
+		}
+		return inspect.WriteBlock(os.Stdout, dump)
+	},
+}
+
+var inspectLogCmd = &cobra.Command{
+	Use:          "log DB",
+	Short:        "Walk the log from newest to oldest record",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logFile := inspectLogFile
+		if logFile == "" {
+			logFile = args[0] + ".log"
+		}
+
+		fm, err := openExisting(args[0], logFile)
+		if err != nil {
+			return err
+		}
+
+		records, err := inspect.Log(fm, logFile)
+		if err != nil {
+			return err
+		}
+		if inspectJSON {
+			return printJSON(records)
+		}
+		return inspect.WriteLog(os.Stdout, records)
+	},
+}
+
+func init() {
+	inspectCmd.PersistentFlags().BoolVar(&inspectJSON, "json", false, "print output as JSON")
+	inspectLogCmd.Flags().StringVar(&inspectLogFile, "file", "", "log file to walk (default DB.log)")
+
+	inspectCmd.AddCommand(inspectBlockCmd)
+	inspectCmd.AddCommand(inspectLogCmd)
+	RootCmd.AddCommand(inspectCmd)
+}
+
+// openExisting opens a file manager for db making sure that neither the
+// database nor filename are created as a side effect of inspecting them.
+func openExisting(db, filename string) (*storage.FileManager, error) {
+	dir, err := storage.Dir(db)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, filename)); err != nil {
+		return nil, err
+	}
+	return storage.NewFileManager(db)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -3,17 +3,29 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/spencercdixon/rql/format"
 	"github.com/spencercdixon/rql/repl"
 	"github.com/spencercdixon/rql/rql"
 	"github.com/spf13/cobra"
 )
 
+// outputFormat is the name of the format results are printed in
+var outputFormat string
+
 // RootCmd represents the base command when called without any subcommands
+//
+// Errors are printed by Execute so cobra is told not to print them as well.
 var RootCmd = &cobra.Command{
-	Use:   "rql",
-	Short: "Console for RQL DB",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:           "rql",
+	Short:         "Console for RQL DB",
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := format.New(outputFormat, format.Options{}); err != nil {
+			return err
+		}
+
 		fmt.Printf("rql (%s)\n", Version)
 		fmt.Println(`Type '\?' for help`)
 		println()
@@ -26,10 +38,20 @@ var RootCmd = &cobra.Command{
 		}
 
 		db := rql.New(dbLoc)
-		repl.Start(db, os.Stdin, os.Stdout)
+		repl.Start(db, os.Stdin, os.Stdout, repl.Options{Format: outputFormat})
+		return nil
 	},
 }
 
+func init() {
+	RootCmd.Flags().StringVar(
+		&outputFormat,
+		"format",
+		format.Default,
+		fmt.Sprintf("output format for results (%s)", strings.Join(format.Names(), ", ")),
+	)
+}
+
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,109 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Aligned renders results as a text table with padded columns, in the style of
+// psql:
+//
+//	 id |     name
+//	----+---------------
+//	  1 | Spencer Dixon
+//	(1 row)
+//
+// When Expanded is set each row is instead printed as a block of
+// column/value pairs.  NULL values are shown as Null.
+type Aligned struct {
+	Expanded bool
+	Null     string
+}
+
+// Format implements Formatter.
+func (a *Aligned) Format(w io.Writer, r *Result) error {
+	if a.Expanded {
+		return a.formatExpanded(w, r)
+	}
+
+	widths := make([]int, len(r.Columns))
+	for i, col := range r.Columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	for _, row := range r.Rows {
+		for i, val := range row {
+			if n := utf8.RuneCountInString(text(val, a.Null)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	header := make([]string, len(r.Columns))
+	rule := make([]string, len(r.Columns))
+	for i, col := range r.Columns {
+		header[i] = center(col, widths[i])
+		rule[i] = strings.Repeat("-", widths[i]+2)
+	}
+	fmt.Fprintf(w, " %s \n", strings.Join(header, " | "))
+	fmt.Fprintln(w, strings.Join(rule, "+"))
+
+	for _, row := range r.Rows {
+		cells := make([]string, len(row))
+		for i, val := range row {
+			if isNumeric(val) {
+				cells[i] = padLeft(text(val, a.Null), widths[i])
+			} else {
+				cells[i] = padRight(text(val, a.Null), widths[i])
+			}
+		}
+		fmt.Fprintf(w, " %s \n", strings.Join(cells, " | "))
+	}
+
+	_, err := fmt.Fprintln(w, rowCount(len(r.Rows)))
+	return err
+}
+
+func (a *Aligned) formatExpanded(w io.Writer, r *Result) error {
+	width := 0
+	for _, col := range r.Columns {
+		if n := utf8.RuneCountInString(col); n > width {
+			width = n
+		}
+	}
+
+	for n, row := range r.Rows {
+		fmt.Fprintf(w, "-[ RECORD %d ]%s\n", n+1, strings.Repeat("-", width))
+		for i, val := range row {
+			fmt.Fprintf(w, "%s | %s\n", padRight(r.Columns[i], width), text(val, a.Null))
+		}
+	}
+
+	if len(r.Rows) == 0 {
+		_, err := fmt.Fprintln(w, "(0 rows)")
+		return err
+	}
+	return nil
+}
+
+func rowCount(n int) string {
+	if n == 1 {
+		return "(1 row)"
+	}
+	return fmt.Sprintf("(%d rows)", n)
+}
+
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", width-utf8.RuneCountInString(s)) + s
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+func center(s string, width int) string {
+	pad := width - utf8.RuneCountInString(s)
+	left := pad / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", pad-left)
+}
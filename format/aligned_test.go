@@ -0,0 +1,52 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestAligned(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&Aligned{}).Format(&buf, users())
+	testutil.Ok(t, err)
+
+	exp := " id |      name       \n" +
+		"----+-----------------\n" +
+		"  1 | Spencer Dixon   \n" +
+		" 12 | Stefan VanBuren \n" +
+		"(2 rows)\n"
+	testutil.Equals(t, exp, buf.String())
+}
+
+func TestAlignedExpanded(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&Aligned{Expanded: true}).Format(&buf, users())
+	testutil.Ok(t, err)
+
+	exp := `-[ RECORD 1 ]----
+id   | 1
+name | Spencer Dixon
+-[ RECORD 2 ]----
+id   | 12
+name | Stefan VanBuren
+`
+	testutil.Equals(t, exp, buf.String())
+}
+
+func TestAlignedNull(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Columns: []string{"id", "company"},
+		Rows:    [][]interface{}{{1, nil}},
+	}
+	err := (&Aligned{Null: "NULL"}).Format(&buf, r)
+	testutil.Ok(t, err)
+
+	exp := " id | company \n" +
+		"----+---------\n" +
+		"  1 | NULL    \n" +
+		"(1 row)\n"
+	testutil.Equals(t, exp, buf.String())
+}
@@ -0,0 +1,36 @@
+package format
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CSV renders results as delimiter separated values with a header row.  A Comma
+// of ',' gives CSV and '\t' gives TSV.  NULL values are written as Null.
+type CSV struct {
+	Comma rune
+	Null  string
+}
+
+// Format implements Formatter.
+func (c *CSV) Format(w io.Writer, r *Result) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = c.Comma
+
+	if err := cw.Write(r.Columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i, val := range row {
+			record[i] = text(val, c.Null)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
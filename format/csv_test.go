@@ -0,0 +1,32 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	r := users()
+	r.Rows = append(r.Rows, []interface{}{3, "Dixon, Spencer"})
+	err := (&CSV{Comma: ','}).Format(&buf, r)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "id,name\n1,Spencer Dixon\n12,Stefan VanBuren\n3,\"Dixon, Spencer\"\n", buf.String())
+}
+
+func TestTSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&CSV{Comma: '\t'}).Format(&buf, users())
+	testutil.Ok(t, err)
+	testutil.Equals(t, "id\tname\n1\tSpencer Dixon\n12\tStefan VanBuren\n", buf.String())
+}
+
+func TestCSVNull(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{Columns: []string{"id", "company"}, Rows: [][]interface{}{{1, nil}}}
+	err := (&CSV{Comma: ','}).Format(&buf, r)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "id,company\n1,\n", buf.String())
+}
@@ -0,0 +1,129 @@
+// Package format renders query results for display.  Each output format is a
+// Formatter which writes a Result to an io.Writer, letting the REPL and other
+// tools switch between human readable tables and machine friendly formats.
+package format
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spencercdixon/rql/storage"
+)
+
+// Result is a set of rows returned by a query.  Every row has one value per
+// column, in the same order as Columns.  A nil value is NULL.
+type Result struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Formatter writes a Result in a particular output format.
+type Formatter interface {
+	Format(w io.Writer, r *Result) error
+}
+
+// Options tweak how results are rendered.  Not every format uses every option.
+type Options struct {
+	// Expanded displays each column of a row on its own line.
+	Expanded bool
+	// Null is the text shown for NULL values by text based formats.  JSON
+	// always uses null.
+	Null string
+}
+
+// Default is the format used when none has been chosen.
+const Default = "aligned"
+
+// ErrUnknownFormat is returned when asking for a format that does not exist.
+var ErrUnknownFormat = errors.New("format: unknown output format")
+
+var formats = map[string]func(opts Options) Formatter{
+	"aligned":  func(opts Options) Formatter { return &Aligned{Expanded: opts.Expanded, Null: opts.Null} },
+	"csv":      func(opts Options) Formatter { return &CSV{Comma: ',', Null: opts.Null} },
+	"tsv":      func(opts Options) Formatter { return &CSV{Comma: '\t', Null: opts.Null} },
+	"json":     func(opts Options) Formatter { return &JSON{} },
+	"ndjson":   func(opts Options) Formatter { return &JSON{Lines: true} },
+	"markdown": func(opts Options) Formatter { return &Markdown{Null: opts.Null} },
+}
+
+// New returns the Formatter registered under name.
+func New(name string, opts Options) (Formatter, error) {
+	newFormatter, ok := formats[name]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownFormat, name)
+	}
+	return newFormatter(opts), nil
+}
+
+// Names returns the names of every available format sorted alphabetically.
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const (
+	// TimestampLayout is how TIMESTAMP values are rendered as text.
+	TimestampLayout = "2006-01-02 15:04:05.999999"
+	// DateLayout is how DATE values are rendered as text.
+	DateLayout = "2006-01-02"
+)
+
+// Date is a DATE value in a Result.  A plain time.Time is a TIMESTAMP, so
+// dates are wrapped to be rendered without a time of day.
+type Date time.Time
+
+// String renders a single value as text.  BLOBs are rendered as hex with a \x
+// prefix and NULL as an empty string.
+func String(val interface{}) string {
+	switch val := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case time.Time:
+		return val.Format(TimestampLayout)
+	case Date:
+		return time.Time(val).Format(DateLayout)
+	case storage.Decimal:
+		return val.String()
+	case []byte:
+		return `\x` + hex.EncodeToString(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// text renders val like String but uses null for NULL values.
+func text(val interface{}, null string) string {
+	if val == nil {
+		return null
+	}
+	return String(val)
+}
+
+// isNumeric reports whether a value should be right aligned.
+func isNumeric(val interface{}) bool {
+	switch val.(type) {
+	case int, int64, float64, storage.Decimal:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		f, err := New(name, Options{})
+		testutil.Ok(t, err)
+		testutil.Assert(t, f != nil, "formatter %s exists", name)
+	}
+
+	_, err := New("yaml", Options{})
+	testutil.Equals(t, ErrUnknownFormat, errors.Cause(err))
+}
+
+func TestNewAlignedExpanded(t *testing.T) {
+	f, err := New("aligned", Options{Expanded: true, Null: "NULL"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, &Aligned{Expanded: true, Null: "NULL"}, f)
+}
+
+// users is the result shared by the formatter tests.
+func users() *Result {
+	return &Result{
+		Columns: []string{"id", "name"},
+		Rows: [][]interface{}{
+			{1, "Spencer Dixon"},
+			{12, "Stefan VanBuren"},
+		},
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		val interface{}
+		exp string
+	}{
+		{"Rio", "Rio"},
+		{42, "42"},
+		{int64(-1 << 40), "-1099511627776"},
+		{true, "true"},
+		{3.25, "3.25"},
+		{time.Date(2018, 1, 2, 3, 4, 5, 600000000, time.UTC), "2018-01-02 03:04:05.6"},
+		{Date(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)), "2018-01-02"},
+		{storage.Decimal{Unscaled: 1250, Scale: 2}, "12.50"},
+		{[]byte{0xde, 0xad}, `\xdead`},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		testutil.Equals(t, tt.exp, String(tt.val))
+	}
+}
@@ -0,0 +1,97 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/spencercdixon/rql/storage"
+)
+
+// JSON renders results as an array of objects keyed by column name.  When Lines
+// is set each object is written on its own line without the surrounding array
+// (newline delimited JSON) so rows can be streamed to other tools.
+//
+// The array is built in full before any of it is written so a value that
+// can't be encoded never leaves half an array behind.
+type JSON struct {
+	Lines bool
+}
+
+// Format implements Formatter.
+func (j *JSON) Format(w io.Writer, r *Result) error {
+	if j.Lines {
+		for _, row := range r.Rows {
+			obj, err := j.object(r.Columns, row)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(obj, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for n, row := range r.Rows {
+		obj, err := j.object(r.Columns, row)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(obj)
+	}
+	buf.WriteString("]\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// object encodes a single row.  The keys are written by hand rather than with
+// a map so they keep the same order as the columns.
+func (j *JSON) object(columns []string, row []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, val := range row {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(columns[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalValue(val)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalValue encodes a single value.  BLOBs are written as the same \x
+// prefixed hex as the text formats rather than encoding/json's base64, and
+// DATEs without a time of day.  DECIMALs are numbers with every digit of
+// their scale kept.  JSON has no NaN or infinity so those are written as the
+// strings the text formats show.
+func marshalValue(val interface{}) ([]byte, error) {
+	switch val := val.(type) {
+	case []byte, Date:
+		return json.Marshal(String(val))
+	case storage.Decimal:
+		return []byte(val.String()), nil
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return json.Marshal(String(val))
+		}
+	}
+	return json.Marshal(val)
+}
@@ -0,0 +1,69 @@
+package format
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&JSON{}).Format(&buf, users())
+	testutil.Ok(t, err)
+	testutil.Equals(t, `[{"id":1,"name":"Spencer Dixon"},{"id":12,"name":"Stefan VanBuren"}]`+"\n", buf.String())
+
+	// BLOBs are hex like the text formats, not base64
+	buf.Reset()
+	r := &Result{Columns: []string{"id", "avatar"}, Rows: [][]interface{}{{1, []byte{0xde, 0xad}}}}
+	err = (&JSON{}).Format(&buf, r)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `[{"id":1,"avatar":"\\xdead"}]`+"\n", buf.String())
+}
+
+func TestJSONColumnTypes(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Columns: []string{"opened", "price", "rate", "limit"},
+		Rows: [][]interface{}{
+			{Date(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)), storage.Decimal{Unscaled: 1250, Scale: 2}, math.NaN(), math.Inf(1)},
+		},
+	}
+	err := (&JSON{}).Format(&buf, r)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `[{"opened":"2018-01-02","price":12.50,"rate":"NaN","limit":"+Inf"}]`+"\n", buf.String())
+}
+
+func TestJSONError(t *testing.T) {
+	// nothing is written when a row can't be encoded
+	var buf bytes.Buffer
+	r := &Result{Columns: []string{"id", "bad"}, Rows: [][]interface{}{{1, "ok"}, {2, make(chan int)}}}
+	err := (&JSON{}).Format(&buf, r)
+	testutil.Assert(t, err != nil, "channels can't be encoded")
+	testutil.Equals(t, "", buf.String())
+}
+
+func TestJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&JSON{}).Format(&buf, &Result{Columns: []string{"id"}})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "[]\n", buf.String())
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := (&JSON{Lines: true}).Format(&buf, users())
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"id":1,"name":"Spencer Dixon"}`+"\n"+`{"id":12,"name":"Stefan VanBuren"}`+"\n", buf.String())
+}
+
+func TestJSONNull(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{Columns: []string{"id", "company"}, Rows: [][]interface{}{{1, nil}}}
+	err := (&JSON{Lines: true}).Format(&buf, r)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"id":1,"company":null}`+"\n", buf.String())
+}
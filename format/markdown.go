@@ -0,0 +1,42 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Markdown renders results as a GitHub flavoured markdown table which can be
+// pasted straight into issues and docs.  NULL values are shown as Null.
+type Markdown struct {
+	Null string
+}
+
+// Format implements Formatter.
+func (m *Markdown) Format(w io.Writer, r *Result) error {
+	header := make([]string, len(r.Columns))
+	rule := make([]string, len(r.Columns))
+	for i, col := range r.Columns {
+		header[i] = escapeMarkdown(col)
+		rule[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "| %s |\n", strings.Join(rule, " | "))
+
+	cells := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i, val := range row {
+			cells[i] = escapeMarkdown(text(val, m.Null))
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeMarkdown keeps values from breaking out of their table cell.
+func escapeMarkdown(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
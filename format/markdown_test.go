@@ -0,0 +1,24 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	r := users()
+	r.Rows = append(r.Rows, []interface{}{3, "a|b"})
+	err := (&Markdown{}).Format(&buf, r)
+	testutil.Ok(t, err)
+
+	exp := `| id | name |
+| --- | --- |
+| 1 | Spencer Dixon |
+| 12 | Stefan VanBuren |
+| 3 | a\|b |
+`
+	testutil.Equals(t, exp, buf.String())
+}
@@ -0,0 +1,213 @@
+// Package inspect decodes the on-disk state of a database for debugging.  It
+// reads blocks and log records through the storage layer and presents them as
+// hex along with the ints and strings they most likely contain.
+package inspect
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spencercdixon/rql/storage"
+)
+
+// Hex is raw bytes which are encoded as a hex string in JSON output.
+type Hex []byte
+
+// MarshalJSON implements json.Marshaler.
+func (h Hex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+// IntValue is an int found at Offset.
+type IntValue struct {
+	Offset int `json:"offset"`
+	Value  int `json:"value"`
+}
+
+// StringValue is a length prefixed string found at Offset.
+type StringValue struct {
+	Offset int    `json:"offset"`
+	Value  string `json:"value"`
+}
+
+// BlockDump is the decoded contents of a single block.
+type BlockDump struct {
+	File    string        `json:"file"`
+	Block   int           `json:"block"`
+	Bytes   Hex           `json:"bytes"`
+	Ints    []IntValue    `json:"ints"`
+	Strings []StringValue `json:"strings"`
+	// Problem describes why the block could not be read cleanly, such as a
+	// checksum mismatch.  The contents are still decoded.
+	Problem string `json:"problem,omitempty"`
+}
+
+// LogRecordDump is a single record from the log.  Log records don't store the
+// types of their values so Values is a best guess at how to decode them.
+type LogRecordDump struct {
+	LSN    int           `json:"lsn"`
+	Offset int           `json:"offset"`
+	Bytes  Hex           `json:"bytes"`
+	Values []interface{} `json:"values"`
+	// Problem describes why the record's block could not be read cleanly.
+	// The record is still decoded when its block's chain can be followed.
+	Problem string `json:"problem,omitempty"`
+}
+
+// Block reads blk and decodes every non zero int at an int aligned offset and
+// every plausible string.
+func Block(fm *storage.FileManager, blk *storage.Block) *BlockDump {
+	p := storage.NewPage(fm)
+	readErr := p.Read(blk)
+	content := p.Contents()
+
+	dump := &BlockDump{
+		File:    blk.FileName,
+		Block:   blk.BlockNum,
+		Bytes:   content,
+		Ints:    []IntValue{},
+		Strings: []StringValue{},
+	}
+	if readErr != nil {
+		dump.Problem = readErr.Error()
+	}
+
+	for offset := 0; offset+storage.IntSize <= len(content); offset += storage.IntSize {
+		if val, err := p.GetInt(offset); err == nil && val != 0 {
+			dump.Ints = append(dump.Ints, IntValue{offset, val})
+		}
+	}
+
+	for offset := 0; offset+storage.IntSize <= len(content); {
+		if str, ok := stringAt(content, offset); ok {
+			dump.Strings = append(dump.Strings, StringValue{offset, str})
+			offset += storage.ByteSizeForVal(str)
+			continue
+		}
+		offset++
+	}
+
+	return dump
+}
+
+// Log walks every record in the log file from newest to oldest.  Blocks are
+// read directly rather than through a LogManager so a block that fails its
+// checksum, or whose chain of records is broken, is reported on its records
+// and the walk carries on with the blocks before it.
+func Log(fm *storage.FileManager, filename string) ([]LogRecordDump, error) {
+	size, err := fm.Size(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []LogRecordDump{}
+	p := storage.NewPage(fm)
+	for blkNum := size - 1; blkNum >= 0; blkNum-- {
+		var problem string
+		if err := p.Read(storage.NewBlock(filename, blkNum)); err != nil {
+			if _, ok := err.(*storage.CorruptPageError); !ok {
+				return nil, err
+			}
+			problem = err.Error()
+		}
+		records = append(records, logRecords(blkNum, p.Contents(), problem)...)
+	}
+	return records, nil
+}
+
+// logRecords follows the back pointers of a log block from its last record to
+// its first.  problem is recorded on every record found.  A pointer that does
+// not lead towards the front of the block ends the walk with an empty record
+// describing the break.
+func logRecords(lsn int, content []byte, problem string) []LogRecordDump {
+	var records []LogRecordDump
+
+	// the first int of a log block points at the last record
+	end := decodeInt(content)
+	if end != 0 && (end < storage.IntSize || end > len(content)-storage.IntSize) {
+		msg := fmt.Sprintf("broken log chain: record pointer %d", end)
+		return append(records, brokenRecord(lsn, 0, joinProblems(problem, msg)))
+	}
+	for end != 0 {
+		prev := decodeInt(content[end:])
+		if prev != 0 && (prev < storage.IntSize || prev > end-storage.IntSize) {
+			msg := fmt.Sprintf("broken log chain: record pointer %d after %d", prev, end)
+			return append(records, brokenRecord(lsn, end, joinProblems(problem, msg)))
+		}
+		raw := content[prev+storage.IntSize : end]
+		records = append(records, LogRecordDump{
+			LSN:     lsn,
+			Offset:  prev + storage.IntSize,
+			Bytes:   raw,
+			Values:  decodeValues(raw),
+			Problem: problem,
+		})
+		end = prev
+	}
+
+	// make sure a damaged block shows up even when it has no records
+	if problem != "" && len(records) == 0 {
+		records = append(records, brokenRecord(lsn, 0, problem))
+	}
+	return records
+}
+
+// brokenRecord is an empty record standing in for the part of a log block
+// that could not be decoded.
+func brokenRecord(lsn, offset int, problem string) LogRecordDump {
+	return LogRecordDump{
+		LSN:     lsn,
+		Offset:  offset,
+		Bytes:   Hex{},
+		Values:  []interface{}{},
+		Problem: problem,
+	}
+}
+
+func joinProblems(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "; " + b
+}
+
+// decodeValues guesses the values stored in raw.  A length prefix followed by
+// that many printable bytes is treated as a string, anything else as an int.
+func decodeValues(raw []byte) []interface{} {
+	values := []interface{}{}
+	for offset := 0; offset+storage.IntSize <= len(raw); {
+		if str, ok := stringAt(raw, offset); ok {
+			values = append(values, str)
+			offset += storage.ByteSizeForVal(str)
+			continue
+		}
+		values = append(values, decodeInt(raw[offset:]))
+		offset += storage.IntSize
+	}
+	return values
+}
+
+// stringAt reports whether content holds a non empty, printable, length
+// prefixed string at offset.
+func stringAt(content []byte, offset int) (string, bool) {
+	length := decodeInt(content[offset:])
+	start := offset + storage.IntSize
+	if length <= 0 || length > len(content)-start {
+		return "", false
+	}
+
+	str := content[start : start+length]
+	for _, b := range str {
+		if b < ' ' || b > '~' {
+			return "", false
+		}
+	}
+	return string(str), true
+}
+
+// decodeInt reads an int the same way storage.Page stores them.
+func decodeInt(b []byte) int {
+	return int(int32(binary.LittleEndian.Uint32(b[:storage.IntSize])))
+}
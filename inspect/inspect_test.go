@@ -0,0 +1,119 @@
+package inspect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spencercdixon/rql/storage"
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestBlock(t *testing.T) {
+	defer cleanUp("inspectblock")
+	fm, err := storage.NewFileManager("inspectblock")
+	testutil.Ok(t, err)
+
+	blk := storage.NewBlock("users.tbl", 0)
+	p := storage.NewPage(fm)
+	p.SetInt(0, 42)
+	p.SetString(8, "hello")
+	p.Write(blk)
+
+	dump := Block(fm, blk)
+	testutil.Equals(t, "users.tbl", dump.File)
+	testutil.Equals(t, p.Size(), len(dump.Bytes))
+	testutil.Equals(t, "", dump.Problem)
+	testutil.Equals(t, IntValue{0, 42}, dump.Ints[0])
+	testutil.Equals(t, []StringValue{{8, "hello"}}, dump.Strings)
+}
+
+func TestLog(t *testing.T) {
+	defer cleanUp("inspectlog")
+	fm, err := storage.NewFileManager("inspectlog")
+	testutil.Ok(t, err)
+	lm, err := storage.NewLogManager("inspectlog.log", fm)
+	testutil.Ok(t, err)
+
+	lm.Append([]interface{}{"hello", "world"})
+	lm.Append([]interface{}{1000, 40})
+	lm.Flush()
+
+	records, err := Log(fm, "inspectlog.log")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(records))
+	testutil.Equals(t, []interface{}{1000, 40}, records[0].Values)
+	testutil.Equals(t, []interface{}{"hello", "world"}, records[1].Values)
+	testutil.Equals(t, 0, records[1].LSN)
+}
+
+func TestLogCorruptBlock(t *testing.T) {
+	defer cleanUp("inspectcorrupt")
+	fm, err := storage.NewFileManager("inspectcorrupt")
+	testutil.Ok(t, err)
+	lm, err := storage.NewLogManager("inspectcorrupt.log", fm)
+	testutil.Ok(t, err)
+
+	// fill the first block so the last record lands in a second one
+	var lsn int
+	for lsn == 0 {
+		lsn, err = lm.Append([]interface{}{1000, 40})
+		testutil.Ok(t, err)
+	}
+	testutil.Ok(t, lm.Flush())
+
+	// damage an unused byte at the end of the second block
+	f, err := os.OpenFile(filepath.Join(fm.Dir, "inspectcorrupt.log"), os.O_RDWR, 0)
+	testutil.Ok(t, err)
+	_, err = f.WriteAt([]byte{0xff}, int64(2*fm.BlockSize-1))
+	testutil.Ok(t, err)
+	testutil.Ok(t, f.Close())
+
+	fm, err = storage.NewFileManager("inspectcorrupt")
+	testutil.Ok(t, err)
+	records, err := Log(fm, "inspectcorrupt.log")
+	testutil.Ok(t, err)
+
+	first := records[0]
+	testutil.Equals(t, 1, first.LSN)
+	testutil.Assert(t, strings.Contains(first.Problem, "checksum mismatch"), "damaged block is reported")
+	testutil.Equals(t, []interface{}{1000, 40}, first.Values)
+
+	last := records[len(records)-1]
+	testutil.Equals(t, 0, last.LSN)
+	testutil.Equals(t, "", last.Problem)
+	testutil.Equals(t, []interface{}{1000, 40}, last.Values)
+}
+
+func TestLogBrokenChain(t *testing.T) {
+	defer cleanUp("inspectchain")
+	fm, err := storage.NewFileManager("inspectchain")
+	testutil.Ok(t, err)
+
+	p := storage.NewPage(fm)
+	testutil.Ok(t, p.SetInt(0, 8))
+	testutil.Ok(t, p.SetInt(8, 100))
+	_, err = p.Append("inspectchain.log")
+	testutil.Ok(t, err)
+
+	records, err := Log(fm, "inspectchain.log")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(records))
+	testutil.Equals(t, "broken log chain: record pointer 100 after 8", records[0].Problem)
+}
+
+func TestHexJSON(t *testing.T) {
+	out, err := json.Marshal(Hex{0xde, 0xad})
+	testutil.Ok(t, err)
+	testutil.Equals(t, `"dead"`, string(out))
+}
+
+// remove the db directories and files that get created while testing
+func cleanUp(dbName string) {
+	home, _ := homedir.Dir()
+	path := filepath.Join(home, "rql", dbName)
+	os.RemoveAll(path)
+}
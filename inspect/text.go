@@ -0,0 +1,42 @@
+package inspect
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// WriteBlock writes a human readable dump of a block.
+func WriteBlock(w io.Writer, dump *BlockDump) error {
+	fmt.Fprintf(w, "[file %s, block %d]\n\n", dump.File, dump.Block)
+	if dump.Problem != "" {
+		fmt.Fprintf(w, "problem: %s\n\n", dump.Problem)
+	}
+	fmt.Fprint(w, hex.Dump(dump.Bytes))
+
+	fmt.Fprintln(w, "\nints:")
+	for _, i := range dump.Ints {
+		fmt.Fprintf(w, "  %4d: %d\n", i.Offset, i.Value)
+	}
+
+	fmt.Fprintln(w, "\nstrings:")
+	for _, s := range dump.Strings {
+		fmt.Fprintf(w, "  %4d: %q\n", s.Offset, s.Value)
+	}
+	return nil
+}
+
+// WriteLog writes a human readable dump of log records, one per line.
+func WriteLog(w io.Writer, records []LogRecordDump) error {
+	for _, r := range records {
+		_, err := fmt.Fprintf(w, "lsn %d offset %d: %s %v", r.LSN, r.Offset, hex.EncodeToString(r.Bytes), r.Values)
+		if err != nil {
+			return err
+		}
+		if r.Problem != "" {
+			fmt.Fprintf(w, " (problem: %s)", r.Problem)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -3,16 +3,21 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/spencercdixon/rql/token"
 )
 
 /*
-	Lexer supports five different token types:
+	Lexer supports eight different token types:
 		1. single character delimiteres, such as the comma
 		2. integer constants, such as 123
-		3. string constants, such as 'john'
-		4. keywords, such as: select, from, and where
-		5. identifiers (ident), such as: STUDENT, x, blahblerg
+		3. float constants, such as 3.14
+		4. string constants, such as 'john'
+		5. blob constants written in hex, such as X'DEADBEEF'
+		6. keywords, such as: select, from, and where
+		7. identifiers (ident), such as: STUDENT, x, user_id2
+		8. parameter placeholders, such as: ? and $1
 */
 type Lexer struct {
 	input        string
@@ -50,18 +55,38 @@ func (l *Lexer) NextToken() token.Token {
 	// tok = newToken(token.GT, l.ch)
 	case '=':
 		tok = newToken(token.ASSIGN, l.ch)
+	case '?':
+		tok = newToken(token.PARAM, l.ch)
+	case '$':
+		if !isDigit(l.peekChar()) {
+			tok = newToken(token.ILLEGAL, l.ch)
+			break
+		}
+		tok.Type = token.PARAM
+		tok.Literal = l.readParam()
+		// positional parameters start at $1
+		if strings.TrimLeft(tok.Literal[1:], "0") == "" {
+			tok.Type = token.ILLEGAL
+		}
+		return tok
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
-		if isLetter(l.ch) {
+		if (l.ch == 'x' || l.ch == 'X') && l.peekChar() == '\'' {
+			l.readChar()
+			tok.Type = token.BLOB_TOK
+			tok.Literal = l.readString()
+			if !isHex(tok.Literal) {
+				tok.Type = token.ILLEGAL
+			}
+		} else if isLetter(l.ch) || l.ch == '_' {
 			tok.Literal = l.readIdentifier()
 			// if this is a keyword use that, otherwise set as IDENT
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT_TOK
-			tok.Literal = l.readNumber()
+			tok.Literal, tok.Type = l.readNumber()
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -92,14 +117,33 @@ func (l *Lexer) peekChar() byte {
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or a float with a fractional part, such as 3.14.
+func (l *Lexer) readNumber() (string, token.Type) {
+	position := l.position
+	tokType := token.Type(token.INT_TOK)
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokType = token.FLOAT_TOK
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+	return l.input[position:l.position], tokType
+}
+
+// readParam reads a numbered placeholder such as $1 including the leading $.
+func (l *Lexer) readParam() string {
 	position := l.position
+	l.readChar()
 	for isDigit(l.ch) {
 		l.readChar()
 	}
@@ -135,7 +179,21 @@ func isLetter(ch byte) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
 }
 
-// TODO: Not taking into consideration floats, octals, etc.
+// isHex reports whether s is whole bytes written as pairs of hex digits.
+func isHex(s string) bool {
+	if len(s)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isDigit(c) && !('a' <= c && c <= 'f') && !('A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// TODO: Not taking into consideration octals, exponents, etc.
 func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
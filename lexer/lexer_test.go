@@ -93,3 +93,384 @@ SELECT name FROM users;
 		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
 	}
 }
+
+func TestParams(t *testing.T) {
+	input := `SELECT name FROM users WHERE id = ? AND company = $12 AND y = $0 AND x = $`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.SELECT, "SELECT"},
+		{token.IDENT, "name"},
+		{token.FROM, "FROM"},
+		{token.IDENT, "users"},
+		{token.WHERE, "WHERE"},
+		{token.IDENT, "id"},
+		{token.ASSIGN, "="},
+		{token.PARAM, "?"},
+		{token.AND, "AND"},
+		{token.IDENT, "company"},
+		{token.ASSIGN, "="},
+		{token.PARAM, "$12"},
+		{token.AND, "AND"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.ILLEGAL, "$0"},
+		{token.AND, "AND"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.ILLEGAL, "$"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	input := `CREATE TABLE accounts (id bigint, active boolean, rate double, price decimal(10, 2), opened date, seen timestamp, avatar blob);
+INSERT INTO accounts VALUES (1, TRUE, 3.14, 10.50, DATE '2018-01-02', x'DEADbeef', false);
+X'ZZ' X'ABC'`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.CREATE, "CREATE"},
+		{token.TABLE, "TABLE"},
+		{token.IDENT, "accounts"},
+		{token.LPAREN, "("},
+		{token.IDENT, "id"},
+		{token.BIGINT, "bigint"},
+		{token.COMMA, ","},
+		{token.IDENT, "active"},
+		{token.BOOLEAN, "boolean"},
+		{token.COMMA, ","},
+		{token.IDENT, "rate"},
+		{token.DOUBLE, "double"},
+		{token.COMMA, ","},
+		{token.IDENT, "price"},
+		{token.DECIMAL, "decimal"},
+		{token.LPAREN, "("},
+		{token.INT_TOK, "10"},
+		{token.COMMA, ","},
+		{token.INT_TOK, "2"},
+		{token.RPAREN, ")"},
+		{token.COMMA, ","},
+		{token.IDENT, "opened"},
+		{token.DATE, "date"},
+		{token.COMMA, ","},
+		{token.IDENT, "seen"},
+		{token.TIMESTAMP, "timestamp"},
+		{token.COMMA, ","},
+		{token.IDENT, "avatar"},
+		{token.BLOB, "blob"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.INSERT, "INSERT"},
+		{token.INTO, "INTO"},
+		{token.IDENT, "accounts"},
+		{token.VALUES, "VALUES"},
+		{token.LPAREN, "("},
+		{token.INT_TOK, "1"},
+		{token.COMMA, ","},
+		{token.TRUE, "TRUE"},
+		{token.COMMA, ","},
+		{token.FLOAT_TOK, "3.14"},
+		{token.COMMA, ","},
+		{token.FLOAT_TOK, "10.50"},
+		{token.COMMA, ","},
+		{token.DATE, "DATE"},
+		{token.STRING_TOK, "2018-01-02"},
+		{token.COMMA, ","},
+		{token.BLOB_TOK, "DEADbeef"},
+		{token.COMMA, ","},
+		{token.FALSE, "false"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "ZZ"},
+		{token.ILLEGAL, "ABC"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestNull(t *testing.T) {
+	input := `SELECT name FROM users WHERE company IS NOT NULL AND age is null`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.SELECT, "SELECT"},
+		{token.IDENT, "name"},
+		{token.FROM, "FROM"},
+		{token.IDENT, "users"},
+		{token.WHERE, "WHERE"},
+		{token.IDENT, "company"},
+		{token.IS, "IS"},
+		{token.NOT, "NOT"},
+		{token.NULL, "NULL"},
+		{token.AND, "AND"},
+		{token.IDENT, "age"},
+		{token.IS, "is"},
+		{token.NULL, "null"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	input := `id int PRIMARY KEY, email varchar(200) unique not null, active bool DEFAULT true CHECK (active IS NOT NULL)`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.IDENT, "id"},
+		{token.INT, "int"},
+		{token.PRIMARY, "PRIMARY"},
+		{token.KEY, "KEY"},
+		{token.COMMA, ","},
+		{token.IDENT, "email"},
+		{token.VARCHAR, "varchar"},
+		{token.LPAREN, "("},
+		{token.INT_TOK, "200"},
+		{token.RPAREN, ")"},
+		{token.UNIQUE, "unique"},
+		{token.NOT, "not"},
+		{token.NULL, "null"},
+		{token.COMMA, ","},
+		{token.IDENT, "active"},
+		{token.BOOLEAN, "bool"},
+		{token.DEFAULT, "DEFAULT"},
+		{token.TRUE, "true"},
+		{token.CHECK, "CHECK"},
+		{token.LPAREN, "("},
+		{token.IDENT, "active"},
+		{token.IS, "IS"},
+		{token.NOT, "NOT"},
+		{token.NULL, "NULL"},
+		{token.RPAREN, ")"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestForeignKeys(t *testing.T) {
+	input := `owner int REFERENCES users (id) ON DELETE CASCADE ON UPDATE SET NULL, FOREIGN KEY (item) REFERENCES items (id) on delete restrict on update no action`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.IDENT, "owner"},
+		{token.INT, "int"},
+		{token.REFERENCES, "REFERENCES"},
+		{token.IDENT, "users"},
+		{token.LPAREN, "("},
+		{token.IDENT, "id"},
+		{token.RPAREN, ")"},
+		{token.ON, "ON"},
+		{token.DELETE, "DELETE"},
+		{token.CASCADE, "CASCADE"},
+		{token.ON, "ON"},
+		{token.UPDATE, "UPDATE"},
+		{token.SET, "SET"},
+		{token.NULL, "NULL"},
+		{token.COMMA, ","},
+		{token.FOREIGN, "FOREIGN"},
+		{token.KEY, "KEY"},
+		{token.LPAREN, "("},
+		{token.IDENT, "item"},
+		{token.RPAREN, ")"},
+		{token.REFERENCES, "REFERENCES"},
+		{token.IDENT, "items"},
+		{token.LPAREN, "("},
+		{token.IDENT, "id"},
+		{token.RPAREN, ")"},
+		{token.ON, "on"},
+		{token.DELETE, "delete"},
+		{token.RESTRICT, "restrict"},
+		{token.ON, "on"},
+		{token.UPDATE, "update"},
+		{token.NO, "no"},
+		{token.ACTION, "action"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestSequences(t *testing.T) {
+	input := `CREATE SEQUENCE user_ids; id serial, _legacy2 int auto_increment, nextval('user_ids')`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.CREATE, "CREATE"},
+		{token.SEQUENCE, "SEQUENCE"},
+		{token.IDENT, "user_ids"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "id"},
+		{token.SERIAL, "serial"},
+		{token.COMMA, ","},
+		{token.IDENT, "_legacy2"},
+		{token.INT, "int"},
+		{token.AUTO_INCREMENT, "auto_increment"},
+		{token.COMMA, ","},
+		{token.IDENT, "nextval"},
+		{token.LPAREN, "("},
+		{token.STRING_TOK, "user_ids"},
+		{token.RPAREN, ")"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestInsertReturning(t *testing.T) {
+	input := `INSERT INTO users VALUES (1, 'Rio'), (2, 'Rio') RETURNING id`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.INSERT, "INSERT"},
+		{token.INTO, "INTO"},
+		{token.IDENT, "users"},
+		{token.VALUES, "VALUES"},
+		{token.LPAREN, "("},
+		{token.INT_TOK, "1"},
+		{token.COMMA, ","},
+		{token.STRING_TOK, "Rio"},
+		{token.RPAREN, ")"},
+		{token.COMMA, ","},
+		{token.LPAREN, "("},
+		{token.INT_TOK, "2"},
+		{token.COMMA, ","},
+		{token.STRING_TOK, "Rio"},
+		{token.RPAREN, ")"},
+		{token.RETURNING, "RETURNING"},
+		{token.IDENT, "id"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestUpdateAssignments(t *testing.T) {
+	input := `UPDATE users SET name = 'Nick', company = 'Rio' WHERE id = 1 RETURNING id`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.UPDATE, "UPDATE"},
+		{token.IDENT, "users"},
+		{token.SET, "SET"},
+		{token.IDENT, "name"},
+		{token.ASSIGN, "="},
+		{token.STRING_TOK, "Nick"},
+		{token.COMMA, ","},
+		{token.IDENT, "company"},
+		{token.ASSIGN, "="},
+		{token.STRING_TOK, "Rio"},
+		{token.WHERE, "WHERE"},
+		{token.IDENT, "id"},
+		{token.ASSIGN, "="},
+		{token.INT_TOK, "1"},
+		{token.RETURNING, "RETURNING"},
+		{token.IDENT, "id"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
+
+func TestDDL(t *testing.T) {
+	input := `ALTER TABLE users ADD COLUMN active bool; ALTER TABLE users RENAME COLUMN company TO employer; TRUNCATE users; DROP TABLE IF EXISTS users`
+	l := New(input)
+
+	tokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.ALTER, "ALTER"},
+		{token.TABLE, "TABLE"},
+		{token.IDENT, "users"},
+		{token.ADD, "ADD"},
+		{token.COLUMN, "COLUMN"},
+		{token.IDENT, "active"},
+		{token.BOOLEAN, "bool"},
+		{token.SEMICOLON, ";"},
+		{token.ALTER, "ALTER"},
+		{token.TABLE, "TABLE"},
+		{token.IDENT, "users"},
+		{token.RENAME, "RENAME"},
+		{token.COLUMN, "COLUMN"},
+		{token.IDENT, "company"},
+		{token.TO, "TO"},
+		{token.IDENT, "employer"},
+		{token.SEMICOLON, ";"},
+		{token.TRUNCATE, "TRUNCATE"},
+		{token.IDENT, "users"},
+		{token.SEMICOLON, ";"},
+		{token.DROP, "DROP"},
+		{token.TABLE, "TABLE"},
+		{token.IF, "IF"},
+		{token.EXISTS, "EXISTS"},
+		{token.IDENT, "users"},
+		{token.EOF, ""},
+	}
+
+	for _, tt := range tokens {
+		tok := l.NextToken()
+		testutil.Equals(t, tt.expectedType, tok.Type)
+		testutil.Equals(t, tt.expectedLiteral, tok.Literal)
+	}
+}
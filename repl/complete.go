@@ -0,0 +1,51 @@
+package repl
+
+import (
+	"strings"
+
+	"github.com/spencercdixon/rql/token"
+)
+
+// completer tab completes the word under the cursor.  Completions keep the
+// case the user started typing in so `SEL` becomes `SELECT` and `sel` becomes
+// `select`.
+type completer struct {
+	words []string
+}
+
+func newCompleter() *completer {
+	return &completer{words: token.Keywords()}
+}
+
+// Do implements readline.AutoCompleter.  It returns the remaining characters
+// of every word that matches the prefix under the cursor along with the length
+// of that prefix.
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	start := pos
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+	if prefix == "" {
+		return nil, 0
+	}
+
+	lower := strings.ToLower(prefix)
+	upper := prefix == strings.ToUpper(prefix)
+
+	var matches [][]rune
+	for _, word := range c.words {
+		if !strings.HasPrefix(word, lower) {
+			continue
+		}
+		if upper {
+			word = strings.ToUpper(word)
+		}
+		matches = append(matches, []rune(word[len(prefix):]))
+	}
+	return matches, len(prefix)
+}
+
+func isWordRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_'
+}
@@ -0,0 +1,23 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestCompleteKeywords(t *testing.T) {
+	c := newCompleter()
+
+	matches, length := c.Do([]rune("SEL"), 3)
+	testutil.Equals(t, [][]rune{[]rune("ECT")}, matches)
+	testutil.Equals(t, 3, length)
+
+	matches, length = c.Do([]rune("select name fr"), 14)
+	testutil.Equals(t, [][]rune{[]rune("om")}, matches)
+	testutil.Equals(t, 2, length)
+
+	matches, length = c.Do([]rune("select "), 7)
+	testutil.Equals(t, 0, len(matches))
+	testutil.Equals(t, 0, length)
+}
@@ -0,0 +1,134 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// HistoryFile is the name of the file in the database directory that stores
+// previously entered commands.
+const HistoryFile = ".rql_history"
+
+// lineReader reads one line of input at a time, showing prompt first when the
+// input is interactive.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	// SaveHistory records a complete command so it can be recalled later.
+	SaveHistory(cmd string)
+	Close() error
+}
+
+// errInterrupt is returned by a lineReader when the user hits ^C.  The
+// current statement buffer is thrown away but the REPL keeps running.
+var errInterrupt = readline.ErrInterrupt
+
+// newLineReader picks a line editor for terminals and falls back to plain
+// scanning for pipes, files and tests.
+func newLineReader(in io.Reader, out io.Writer, historyPath string) (lineReader, error) {
+	if f, ok := in.(*os.File); ok && readline.IsTerminal(int(f.Fd())) {
+		return newTerminalReader(f, out, historyPath)
+	}
+	return &scanReader{scanner: bufio.NewScanner(in), out: out}, nil
+}
+
+// scanReader reads lines from any io.Reader.  A nil out means no prompts are
+// shown, which is how scripts run with \i stay quiet.
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scanReader) ReadLine(prompt string) (string, error) {
+	if r.out != nil {
+		fmt.Fprint(r.out, prompt)
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *scanReader) SaveHistory(cmd string) {}
+
+func (r *scanReader) Close() error { return nil }
+
+// terminalReader provides line editing, persistent history and tab completion
+// when the REPL is attached to a terminal.
+type terminalReader struct {
+	rl *readline.Instance
+}
+
+func newTerminalReader(in *os.File, out io.Writer, historyPath string) (*terminalReader, error) {
+	if historyPath != "" {
+		if err := os.MkdirAll(filepath.Dir(historyPath), 0777); err != nil {
+			return nil, err
+		}
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Stdin:                  in,
+		Stdout:                 out,
+		HistoryFile:            historyPath,
+		DisableAutoSaveHistory: true,
+		AutoComplete:           newCompleter(),
+		InterruptPrompt:        "^C",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &terminalReader{rl: rl}, nil
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	r.rl.SetPrompt(prompt)
+	return r.rl.Readline()
+}
+
+func (r *terminalReader) SaveHistory(cmd string) {
+	// history is stored one entry per line so multi-line statements are
+	// flattened before saving
+	r.rl.SaveHistory(strings.Join(strings.Fields(cmd), " "))
+}
+
+func (r *terminalReader) Close() error {
+	return r.rl.Close()
+}
+
+// splitStatements adds input to buf and returns every statement terminated by
+// a semicolon, along with whatever is left over waiting for more input.
+// Semicolons inside string constants do not end a statement.
+func splitStatements(buf, input string) (stmts []string, rest string) {
+	text := buf + input
+	inString := false
+	start := 0
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\'':
+			inString = !inString
+		case ';':
+			if inString {
+				continue
+			}
+			if stmt := strings.TrimSpace(text[start : i+1]); stmt != ";" {
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+
+	rest = text[start:]
+	if strings.TrimSpace(rest) == "" {
+		rest = ""
+	}
+	return stmts, rest
+}
@@ -0,0 +1,29 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		buf   string
+		input string
+		stmts []string
+		rest  string
+	}{
+		{"", "SELECT name FROM users;\n", []string{"SELECT name FROM users;"}, ""},
+		{"", "SELECT name\n", nil, "SELECT name\n"},
+		{"SELECT name\n", "FROM users;\n", []string{"SELECT name\nFROM users;"}, ""},
+		{"", "SELECT a FROM b; SELECT c", []string{"SELECT a FROM b;"}, " SELECT c"},
+		{"", "INSERT INTO t (a) VALUES ('x;y');", []string{"INSERT INTO t (a) VALUES ('x;y');"}, ""},
+		{"", ";;\n", nil, ""},
+	}
+
+	for _, tt := range tests {
+		stmts, rest := splitStatements(tt.buf, tt.input)
+		testutil.Equals(t, tt.stmts, stmts)
+		testutil.Equals(t, tt.rest, rest)
+	}
+}
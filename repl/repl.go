@@ -5,43 +5,307 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/spencercdixon/rql/format"
 	"github.com/spencercdixon/rql/rql"
 )
 
-const PromptTemplate = "rql(%s)=# "
+const (
+	// PromptTemplate is shown when the REPL is ready for a new command.
+	PromptTemplate = "rql(%s)=# "
+	// ContinuationTemplate is shown while a statement spans multiple lines and
+	// has not yet been terminated with a semicolon.
+	ContinuationTemplate = "rql(%s)-# "
+	// DefaultNull is how NULL values are displayed until changed with \pset.
+	DefaultNull = "NULL"
+)
 
-func Start(db *rql.Database, in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+// Options configure a REPL session before it starts.
+type Options struct {
+	// Format is the name of the output format used for results.  Defaults to
+	// format.Default when empty.
+	Format string
+}
 
-	for {
-		fmt.Printf(PromptTemplate, db.Path)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
+// session holds the state of a single REPL run.  Meta commands toggle settings
+// on the session which are then used when executing statements.
+type session struct {
+	db *rql.Database
+	// stdout is where prompts and meta command feedback are always written
+	stdout io.Writer
+	// out is where statement results are written.  It is the same as stdout
+	// unless redirected with \o
+	out io.Writer
+	// outFile is the file results are being redirected to, if any
+	outFile *os.File
+	// timing reports how long each statement took to execute
+	timing bool
+	// format is the name of the output format used for results
+	format string
+	// expanded displays each column of a result on its own line
+	expanded bool
+	// null is the text shown for NULL values in results
+	null string
+	// quit is set once \q has been issued
+	quit bool
+}
+
+// Start runs the REPL reading commands from in until it is exhausted or the
+// user quits with \q.  Statements may span several lines and are executed once
+// terminated with a semicolon.  When in is a terminal, line editing, tab
+// completion and history saved in the database directory are available.
+func Start(db *rql.Database, in io.Reader, out io.Writer, opts Options) {
+	s := &session{db: db, stdout: out, out: out, format: opts.Format, null: DefaultNull}
+	if s.format == "" {
+		s.format = format.Default
+	}
+	defer s.closeOutput()
+
+	var historyPath string
+	if dir, err := db.Dir(); err == nil {
+		historyPath = filepath.Join(dir, HistoryFile)
+	}
+
+	lr, err := newLineReader(in, out, historyPath)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return
+	}
+	defer lr.Close()
+
+	_, terminal := lr.(*terminalReader)
+	s.run(lr, terminal)
+}
+
+// run reads commands from lr until it is exhausted.  Readers that have nowhere
+// to show prompts, like scripts run with \i, stay quiet.  A statement left
+// unfinished when the input ends is executed for piped input and scripts but
+// thrown away at a terminal, where it means the user gave up on it with ^D.
+func (s *session) run(lr lineReader, terminal bool) {
+	var buf string
+
+	for !s.quit {
+		template := PromptTemplate
+		if buf != "" {
+			template = ContinuationTemplate
+		}
+		prompt := fmt.Sprintf(template, s.db.Path)
+
+		line, err := lr.ReadLine(prompt)
+		if err == errInterrupt {
+			buf = ""
+			continue
+		}
+		if err != nil {
+			break
+		}
+
+		// meta commands are handled straight away, even part way through a
+		// statement, which carries on once they are done
+		if strings.HasPrefix(strings.TrimSpace(line), "\\") {
+			line = strings.TrimSpace(line)
+			lr.SaveHistory(line)
+			s.handleMetaCommand(line)
+			continue
 		}
-		line := scanner.Text()
 
-		if strings.HasPrefix(line, "\\") {
-			handleMetaCommand(line)
-		} else {
-			fmt.Println("Executing")
+		var stmts []string
+		stmts, buf = splitStatements(buf, line+"\n")
+		for _, stmt := range stmts {
+			lr.SaveHistory(stmt)
+			s.execute(stmt)
 		}
 	}
+
+	// run whatever is left when a script ends without a final semicolon
+	if stmt := strings.TrimSpace(buf); stmt != "" && !s.quit && !terminal {
+		s.execute(stmt)
+	}
+}
+
+func (s *session) execute(stmt string) {
+	start := time.Now()
+	fmt.Fprintln(s.out, "Executing")
+	if s.timing {
+		elapsed := time.Since(start)
+		fmt.Fprintf(s.stdout, "Time: %.3f ms\n", float64(elapsed)/float64(time.Millisecond))
+	}
 }
 
-func handleMetaCommand(line string) {
-	if line == `\q` {
-		fmt.Println("Exiting...")
-	} else if line == `\?` {
-		printHelp()
+//-----------------
+// Meta Commands
+//-----------------
+
+// metaCommand is a backslash command that changes the behaviour of the REPL
+// rather than being sent to the database.
+type metaCommand struct {
+	name  string
+	usage string
+	help  string
+	fn    func(s *session, args []string) error
+}
+
+// metaCommands is ordered so that help output is stable.
+var metaCommands []metaCommand
+
+func init() {
+	metaCommands = []metaCommand{
+		{`\i`, `\i FILE`, "execute commands from file", (*session).include},
+		{`\o`, `\o [FILE]`, "send query results to file or back to stdout", (*session).output},
+		{`\pset`, `\pset [OPTION [VALUE]]`, "show or set the format or null display of results", (*session).pset},
+		{`\timing`, `\timing`, "toggle timing of commands", (*session).toggleTiming},
+		{`\x`, `\x`, "toggle expanded output", (*session).toggleExpanded},
+		{`\?`, `\?`, "help", (*session).help},
+		{`\q`, `\q`, "quit", (*session).exit},
 	}
 }
 
-func printHelp() {
-	fmt.Println("\n\rrql help:")
-	fmt.Println(` \q - quit `)
-	fmt.Println(` \? - help `)
-	println()
+func (s *session) handleMetaCommand(line string) {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	for _, mc := range metaCommands {
+		if mc.name == name {
+			if err := mc.fn(s, args); err != nil {
+				fmt.Fprintf(s.stdout, "%s: %s\n", name, err)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(s.stdout, "invalid command %s. Try \\? for help.\n", name)
+}
+
+func (s *session) include(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("missing required argument")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s.run(&scanReader{scanner: bufio.NewScanner(f)}, false)
+	return nil
+}
+
+func (s *session) output(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+	if err := s.closeOutput(); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	s.outFile = f
+	s.out = f
+	return nil
+}
+
+// closeOutput closes any file results are being redirected to and points
+// output back at stdout.
+func (s *session) closeOutput() error {
+	s.out = s.stdout
+	if s.outFile == nil {
+		return nil
+	}
+	err := s.outFile.Close()
+	s.outFile = nil
+	return err
+}
+
+func (s *session) pset(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(s.stdout, "format %s\n", s.format)
+		fmt.Fprintf(s.stdout, "null   %q\n", s.null)
+		return nil
+	}
+	switch args[0] {
+	case "format":
+		return s.psetFormat(args[1:])
+	case "null":
+		return s.psetNull(args[1:])
+	}
+	return fmt.Errorf("unknown option: %s", args[0])
+}
+
+func (s *session) psetFormat(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(s.stdout, "Output format is %s.\n", s.format)
+		return nil
+	}
+
+	if _, err := format.New(args[0], format.Options{}); err != nil {
+		return fmt.Errorf("allowed formats are %s", strings.Join(format.Names(), ", "))
+	}
+	s.format = args[0]
+	fmt.Fprintf(s.stdout, "Output format is %s.\n", s.format)
+	return nil
+}
+
+// psetNull sets the text shown for NULL values.  With no argument NULL values
+// are shown as empty strings.  Like psql, the text may be wrapped in single
+// quotes which are not part of it.
+func (s *session) psetNull(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+	s.null = ""
+	if len(args) == 1 {
+		s.null = unquote(args[0])
+	}
+	fmt.Fprintf(s.stdout, "Null display is %q.\n", s.null)
+	return nil
+}
+
+func (s *session) toggleTiming(args []string) error {
+	s.timing = !s.timing
+	fmt.Fprintf(s.stdout, "Timing is %s.\n", onOff(s.timing))
+	return nil
+}
+
+func (s *session) toggleExpanded(args []string) error {
+	s.expanded = !s.expanded
+	fmt.Fprintf(s.stdout, "Expanded display is %s.\n", onOff(s.expanded))
+	return nil
+}
+
+func (s *session) help(args []string) error {
+	fmt.Fprintln(s.stdout, "\nrql help:")
+	for _, mc := range metaCommands {
+		fmt.Fprintf(s.stdout, " %-10s - %s\n", mc.usage, mc.help)
+	}
+	fmt.Fprintln(s.stdout)
+	return nil
+}
+
+func (s *session) exit(args []string) error {
+	s.quit = true
+	return nil
+}
+
+// unquote strips one pair of single quotes surrounding arg, if it has them.
+func unquote(arg string) string {
+	if len(arg) >= 2 && strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'") {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
 }
@@ -0,0 +1,114 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spencercdixon/rql/rql"
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestQuitStopsReading(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("\\q\nSELECT name FROM users;\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	testutil.Assert(t, !strings.Contains(out.String(), "Executing"), "statements after \\q are not run")
+}
+
+func TestToggles(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("\\timing\n\\x\n\\x\n\\nope\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	testutil.Assert(t, strings.Contains(out.String(), "Timing is on."), "timing toggled on")
+	testutil.Assert(t, strings.Contains(out.String(), "Expanded display is on."), "expanded toggled on")
+	testutil.Assert(t, strings.Contains(out.String(), "Expanded display is off."), "expanded toggled off")
+	testutil.Assert(t, strings.Contains(out.String(), "invalid command \\nope"), "unknown commands are reported")
+}
+
+func TestIncludeAndOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repl")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "script.sql")
+	results := filepath.Join(dir, "results.txt")
+	err = ioutil.WriteFile(script, []byte("SELECT name FROM users;\n"), 0644)
+	testutil.Ok(t, err)
+
+	out := &bytes.Buffer{}
+	in := strings.NewReader("\\o " + results + "\n\\i " + script + "\n\\o\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	written, err := ioutil.ReadFile(results)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "Executing\n", string(written))
+	testutil.Assert(t, !strings.Contains(out.String(), "Executing"), "results were redirected")
+}
+
+func TestMultiLineStatements(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("CREATE TABLE users (\n  id int\n);\nSELECT id FROM users; SELECT id\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	testutil.Equals(t, 3, strings.Count(out.String(), "Executing"))
+	testutil.Equals(t, 3, strings.Count(out.String(), "rql(tmp)-# "))
+}
+
+func TestLeftoverStatement(t *testing.T) {
+	run := func(input string, terminal bool) string {
+		out := &bytes.Buffer{}
+		s := &session{db: rql.New("tmp"), stdout: out, out: out}
+		s.run(&scanReader{scanner: bufio.NewScanner(strings.NewReader(input))}, terminal)
+		return out.String()
+	}
+
+	testutil.Equals(t, "Executing\n", run("SELECT id\nFROM users", false))
+	testutil.Equals(t, "", run("SELECT id\nFROM users", true))
+}
+
+func TestMetaCommandInsideStatement(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("SELECT id\n\\timing\nFROM users;\nSELECT id\n\\q\nFROM users;\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	testutil.Assert(t, strings.Contains(out.String(), "Timing is on."), "meta commands work part way through a statement")
+	testutil.Equals(t, 1, strings.Count(out.String(), "Executing"))
+}
+
+func TestPsetFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("\\pset format\n\\pset format csv\n\\pset format yaml\n\\pset\n")
+
+	Start(rql.New("tmp"), in, out, Options{Format: "json"})
+
+	testutil.Assert(t, strings.Contains(out.String(), "Output format is json."), "format from options is used")
+	testutil.Assert(t, strings.Contains(out.String(), "Output format is csv."), "format can be changed")
+	testutil.Assert(t, strings.Contains(out.String(), "allowed formats are"), "unknown formats are rejected")
+	testutil.Assert(t, strings.Contains(out.String(), "format csv\n"), "\\pset shows current settings")
+}
+
+func TestPsetNull(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("\\pset\n\\pset null (null)\n\\pset null '(nil)'\n\\pset null ''\n\\pset null\n\\pset null a b\n")
+
+	Start(rql.New("tmp"), in, out, Options{})
+
+	testutil.Assert(t, strings.Contains(out.String(), `null   "NULL"`), "NULL is shown as NULL by default")
+	testutil.Assert(t, strings.Contains(out.String(), `Null display is "(null)".`), "null display can be changed")
+	testutil.Assert(t, strings.Contains(out.String(), `Null display is "(nil)".`), "quotes around the null display are stripped")
+	testutil.Assert(t, !strings.Contains(out.String(), `"''"`), "empty quotes are an empty null display")
+	testutil.Assert(t, strings.Contains(out.String(), `Null display is "".`), "null display can be cleared")
+	testutil.Assert(t, strings.Contains(out.String(), "too many arguments"), "only one null string is allowed")
+}
@@ -1,5 +1,7 @@
 package rql
 
+import "github.com/spencercdixon/rql/storage"
+
 // New creates a new database opening up a connection to the file where pages
 // will be stored.
 func New(path string) *Database {
@@ -10,3 +12,8 @@ func New(path string) *Database {
 type Database struct {
 	Path string
 }
+
+// Dir returns the directory on disk where this database's files are kept.
+func (db *Database) Dir() (string, error) {
+	return storage.Dir(db.Path)
+}
@@ -1,19 +1,79 @@
 package storage
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 const (
-	// BlockSize denotes the number of bytes in a Block.  Most DBMS use the
+	// DefaultBlockSize denotes the number of bytes in a Block unless another
+	// size is chosen when the database is created.  Most DBMS use the
 	// underlying OS's block size (generally 4KB) but for educational purposes
 	// we're using an artifically low size to generate a lot of blocks.
-	BlockSize = 400
+	DefaultBlockSize = 400
+	// MinBlockSize is the smallest block size a database can be created with.
+	MinBlockSize = 64
+	// MaxBlockSize is the largest block size a database can be created with.
+	MaxBlockSize = 1 << 20
+	// PageHeaderSize is the number of bytes reserved at the front of every
+	// block for the page header.  The header holds a CRC32C checksum of the
+	// rest of the block.
+	PageHeaderSize = 4
 	// IntSize represents how many bytes we will let the INT type be in our
-	// database. We're using uint32 so this will be 4 bytes.
+	// database. We're using int32 so this will be 4 bytes.
 	IntSize = 4
+	// BigIntSize is the number of bytes used by the BIGINT and DECIMAL types.
+	// DECIMAL values are stored as an unscaled BIGINT.
+	BigIntSize = 8
+	// BoolSize is the number of bytes used by the BOOLEAN type.
+	BoolSize = 1
+	// FloatSize is the number of bytes used by the FLOAT and DOUBLE types.
+	// Both are stored as 64 bit IEEE 754 floats.
+	FloatSize = 8
+	// DateSize is the number of bytes used by the DATE type.  Dates are stored
+	// as the number of days since the unix epoch.
+	DateSize = 4
+	// TimestampSize is the number of bytes used by the TIMESTAMP type.
+	// Timestamps are stored as microseconds since the unix epoch in UTC.
+	TimestampSize = 8
 )
 
 var (
-	// ErrPageFull is returned when trying to et an int or string to a page
+	// ErrPageFull is returned when trying to set an int or string to a page
 	// that does not have enough room to set those primitives.
 	ErrPageFull = errors.New("storage: not enough bytes available in this pages content")
+	// ErrOutOfBounds is returned when reading from an offset outside of a
+	// page, or when a strings length prefix runs past the end of the page.
+	ErrOutOfBounds = errors.New("storage: offset is outside of the pages content")
+	// ErrOutOfRange is returned when a value is too big or small to be stored
+	// in the type it is being set as.
+	ErrOutOfRange = errors.New("storage: value out of range for its type")
+	// ErrNotDatabase is returned when opening a directory that has files in it
+	// but no valid database header.
+	ErrNotDatabase = errors.New("storage: not an rql database or created by an older version of rql")
+	// ErrIncompatibleFormat is returned when a database's header describes a
+	// layout this build of rql can't read.
+	ErrIncompatibleFormat = errors.New("storage: incompatible database format")
+	// ErrNoCurrentValue is returned when asking a sequence for its current
+	// value before it has handed one out.
+	ErrNoCurrentValue = errors.New("storage: sequence has no current value")
 )
+
+// CorruptPageError is returned when the contents of a block read from disk do
+// not match the checksum stored in its header.  This happens after a torn
+// write or when the bytes on disk have been damaged.
+type CorruptPageError struct {
+	Block *Block
+	// Stored is the checksum found in the page header
+	Stored uint32
+	// Computed is the checksum of the contents actually read
+	Computed uint32
+}
+
+func (e *CorruptPageError) Error() string {
+	return fmt.Sprintf(
+		"storage: checksum mismatch in %s: stored %08x, computed %08x",
+		e.Block, e.Stored, e.Computed,
+	)
+}
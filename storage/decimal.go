@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Decimal is a DECIMAL value.  It is stored as Unscaled, an ordinary BIGINT,
+// and is worth Unscaled / 10^Scale.  The scale isn't stored with the value, it
+// comes from the column, so 12.50 in a DECIMAL(10, 2) is {1250, 2}.
+type Decimal struct {
+	Unscaled int64
+	Scale    int
+}
+
+// String renders d with exactly Scale digits after the decimal point.
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.Unscaled, 10)
+	if d.Scale <= 0 {
+		return digits
+	}
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	// pad so there is always a digit before the point
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
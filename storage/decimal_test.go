@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		val Decimal
+		exp string
+	}{
+		{Decimal{1250, 2}, "12.50"},
+		{Decimal{-5, 2}, "-0.05"},
+		{Decimal{7, 3}, "0.007"},
+		{Decimal{0, 2}, "0.00"},
+		{Decimal{42, 0}, "42"},
+		{Decimal{math.MinInt64, 4}, "-922337203685477.5808"},
+	}
+
+	for _, tt := range tests {
+		testutil.Equals(t, tt.exp, tt.val.String())
+	}
+}
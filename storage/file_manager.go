@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
@@ -17,21 +19,46 @@ type FileManager struct {
 	// IsNew is a flag to represent whether or not the file manager created the
 	// new directory for this database to live in.
 	IsNew bool
+	// BlockSize is the number of bytes in each block of this database.  It is
+	// chosen when the database is created and stored in its header.
+	BlockSize int
 	// openFiles are all the files that have been opened and are currently in use
 	openFiles map[string]*os.File
+	// sequenceMu serializes Sequence.Next across every handle on this
+	// database's sequences so two of them can't read the same last value
+	sequenceMu sync.Mutex
+}
+
+// Options configure a database when it is first created.
+type Options struct {
+	// BlockSize is the number of bytes in each block.  Defaults to
+	// DefaultBlockSize.  Opening an existing database with a different block
+	// size is an error.
+	BlockSize int
 }
 
 // NewFileManager returns a new file manager.  It checks for the existence of
 // the database 'db' and if none exists it will create the necessary
 // directories/files. TODO: tmp file removal.
 func NewFileManager(db string) (*FileManager, error) {
-	home, err := homedir.Dir()
+	return OpenFileManager(db, Options{})
+}
+
+// OpenFileManager is like NewFileManager but lets the caller configure the
+// database when it gets created.  The header of an existing database is
+// validated and databases this build can't read are refused.
+func OpenFileManager(db string, opts Options) (*FileManager, error) {
+	if opts.BlockSize != 0 {
+		if err := validateBlockSize(opts.BlockSize); err != nil {
+			return nil, err
+		}
+	}
+
+	dbLoc, err := Dir(db)
 	if err != nil {
 		return nil, err
 	}
 
-	// Store all databases in ~/rql/dbname
-	dbLoc := filepath.Join(home, "rql", db)
 	fm := &FileManager{
 		Dir:       dbLoc,
 		openFiles: make(map[string]*os.File),
@@ -45,20 +72,88 @@ func NewFileManager(db string) (*FileManager, error) {
 		}
 	}
 
+	h, err := fm.loadHeader(opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening database %s", db)
+	}
+	fm.BlockSize = h.blockSize
+
 	// TODO: remove any 'temp' files in the database from previous boots
 	return fm, nil
 }
 
+// loadHeader reads the database header, writing one first if the database
+// has no files yet.
+func (fm *FileManager) loadHeader(opts Options) (*header, error) {
+	h, err := readHeader(fm.Dir)
+	if os.IsNotExist(errors.Cause(err)) {
+		hasData, err := hasDataFiles(fm.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if hasData {
+			return nil, ErrNotDatabase
+		}
+
+		h = &header{version: FormatVersion, blockSize: opts.BlockSize}
+		if h.blockSize == 0 {
+			h.blockSize = DefaultBlockSize
+		}
+		return h, writeHeader(fm.Dir, h)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	if opts.BlockSize != 0 && opts.BlockSize != h.blockSize {
+		return nil, errors.Errorf("database was created with a block size of %d, not %d", h.blockSize, opts.BlockSize)
+	}
+	return h, nil
+}
+
+// Dir returns the directory the database 'db' is stored in.  All databases
+// live in ~/rql/dbname.
+func Dir(db string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "rql", db), nil
+}
+
+// Exists reports whether the database 'db' has been created.  Its directory
+// alone isn't enough since the REPL makes it to keep history in.
+func Exists(db string) (bool, error) {
+	dir, err := Dir(db)
+	if err != nil {
+		return false, err
+	}
+	if exists(filepath.Join(dir, HeaderFile)) {
+		return true, nil
+	}
+	return hasDataFiles(dir)
+}
+
+// Read reads the block blk into content.  Blocks past the end of the file have
+// not been written yet and are read as all zeros.
 func (fm *FileManager) Read(blk *Block, content []byte) error {
 	file, err := fm.getFile(blk.FileName)
 	if err != nil {
 		return err
 	}
-	offset := blk.BlockNum * BlockSize
-	if _, err := file.ReadAt(content, int64(offset)); err != nil {
-		return err
+	offset := blk.BlockNum * fm.BlockSize
+	n, err := file.ReadAt(content, int64(offset))
+	if err == io.EOF {
+		// reading past the end of the file gives back an empty block
+		for i := n; i < len(content); i++ {
+			content[i] = 0
+		}
+		return nil
 	}
-	return nil
+	return err
 }
 
 // Write writes the content bytes to the given blk.  If the file does not exist
@@ -68,7 +163,7 @@ func (fm *FileManager) Write(blk *Block, content []byte) error {
 	if err != nil {
 		return err
 	}
-	offset := int64(blk.BlockNum * BlockSize)
+	offset := int64(blk.BlockNum * fm.BlockSize)
 	if _, err := file.WriteAt(content, offset); err != nil {
 		return err
 	}
@@ -93,6 +188,65 @@ func (fm *FileManager) Append(filename string, content []byte) (*Block, error) {
 	return blk, nil
 }
 
+// Truncate closes filename and shrinks it to its first blocks blocks.
+// Truncating to 0 empties the file but keeps it around.  ErrOutOfBounds is
+// returned rather than growing the file when it is shorter than blocks.  Like
+// Remove, a file that does not exist is an error rather than being created.
+func (fm *FileManager) Truncate(filename string, blocks int) error {
+	path := filepath.Join(fm.Dir, filename)
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, "truncating file")
+	}
+	if blocks < 0 || int64(blocks*fm.BlockSize) > info.Size() {
+		return ErrOutOfBounds
+	}
+	if err := fm.closeFile(filename); err != nil {
+		return err
+	}
+	if err := os.Truncate(path, int64(blocks*fm.BlockSize)); err != nil {
+		return errors.Wrap(err, "truncating file")
+	}
+	return nil
+}
+
+// Rename moves the file oldname to newname, replacing newname if it exists.
+func (fm *FileManager) Rename(oldname, newname string) error {
+	if err := fm.closeFile(oldname); err != nil {
+		return err
+	}
+	if err := fm.closeFile(newname); err != nil {
+		return err
+	}
+	err := os.Rename(filepath.Join(fm.Dir, oldname), filepath.Join(fm.Dir, newname))
+	if err != nil {
+		return errors.Wrap(err, "renaming file")
+	}
+	return nil
+}
+
+// Remove closes and deletes filename.  Removing a file that does not exist
+// is an error that satisfies os.IsNotExist once unwrapped with errors.Cause.
+func (fm *FileManager) Remove(filename string) error {
+	if err := fm.closeFile(filename); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(fm.Dir, filename)); err != nil {
+		return errors.Wrap(err, "removing file")
+	}
+	return nil
+}
+
+// closeFile closes filename if it is open and forgets its descriptor.
+func (fm *FileManager) closeFile(filename string) error {
+	file, ok := fm.openFiles[filename]
+	if !ok {
+		return nil
+	}
+	delete(fm.openFiles, filename)
+	return file.Close()
+}
+
 // getFile finds an open descriptor that is being saved or creates a new one
 // with the proper settings if not found.
 func (fm *FileManager) getFile(filename string) (*os.File, error) {
@@ -113,18 +267,22 @@ func (fm *FileManager) getFile(filename string) (*os.File, error) {
 	return file, nil
 }
 
-// Size returns the current block number for a given file.
-func (fm *FileManager) Size(filename string) (int, error) {
+// Stat returns the FileInfo describing filename.
+func (fm *FileManager) Stat(filename string) (os.FileInfo, error) {
 	file, err := fm.getFile(filename)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	return file.Stat()
+}
 
-	info, err := file.Stat()
+// Size returns the current block number for a given file.
+func (fm *FileManager) Size(filename string) (int, error) {
+	info, err := fm.Stat(filename)
 	if err != nil {
 		return 0, err
 	}
 
 	bytes := info.Size()
-	return int(bytes / BlockSize), nil
+	return int(bytes / int64(fm.BlockSize)), nil
 }
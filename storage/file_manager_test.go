@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
 	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
 	"github.com/spencercdixon/rql/testutil"
 )
 
@@ -20,33 +22,39 @@ func TestFileManager(t *testing.T) {
 	blk2 := NewBlock("users.tbl", 2)
 
 	// read/write string and int
-	p.Read(blk1)
-	p.SetString(0, "hello")
-	p.SetInt(250, 42)
-	p.Write(blk1)
-	hello := p.GetString(0)
-	life := p.GetInt(250)
+	testutil.Ok(t, p.Read(blk1))
+	testutil.Ok(t, p.SetString(0, "hello"))
+	testutil.Ok(t, p.SetInt(250, 42))
+	testutil.Ok(t, p.Write(blk1))
+	hello, err := p.GetString(0)
+	testutil.Ok(t, err)
+	life, err := p.GetInt(250)
+	testutil.Ok(t, err)
 	testutil.Equals(t, "hello", hello)
 	testutil.Equals(t, 42, life)
 
 	// read/write second block
-	p.Read(blk2)
-	p.SetString(0, "hello")
-	p.SetInt(100, 42)
-	p.Write(blk2)
-	hello = p.GetString(0)
-	life = p.GetInt(100)
+	testutil.Ok(t, p.Read(blk2))
+	testutil.Ok(t, p.SetString(0, "hello"))
+	testutil.Ok(t, p.SetInt(100, 42))
+	testutil.Ok(t, p.Write(blk2))
+	hello, err = p.GetString(0)
+	testutil.Ok(t, err)
+	life, err = p.GetInt(100)
+	testutil.Ok(t, err)
 	testutil.Equals(t, "hello", hello)
 	testutil.Equals(t, 42, life)
 
 	// confirm our first block still persisted
-	p.Read(blk1)
-	hello = p.GetString(0)
+	testutil.Ok(t, p.Read(blk1))
+	hello, err = p.GetString(0)
+	testutil.Ok(t, err)
 	testutil.Equals(t, "hello", hello)
 
 	// confirm our second block persisted
-	p.Read(blk2)
-	life = p.GetInt(100)
+	testutil.Ok(t, p.Read(blk2))
+	life, err = p.GetInt(100)
+	testutil.Ok(t, err)
 	testutil.Equals(t, 42, life)
 }
 
@@ -56,3 +64,108 @@ func cleanUp(dbName string) {
 	path := filepath.Join(home, "rql", dbName)
 	os.RemoveAll(path)
 }
+
+func TestFileManagerHeader(t *testing.T) {
+	defer cleanUp("header")
+	fm, err := OpenFileManager("header", Options{BlockSize: 4096})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4096, fm.BlockSize)
+	testutil.Equals(t, 4096-PageHeaderSize, NewPage(fm).Size())
+
+	// the block size is remembered without being asked for
+	fm, err = NewFileManager("header")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4096, fm.BlockSize)
+
+	// but asking for a different one is refused
+	_, err = OpenFileManager("header", Options{BlockSize: 8192})
+	testutil.Assert(t, err != nil, "mismatched block size is refused")
+}
+
+func TestExists(t *testing.T) {
+	defer cleanUp("exists")
+	ok, err := Exists("exists")
+	testutil.Ok(t, err)
+	testutil.Assert(t, !ok, "missing database doesn't exist")
+
+	// a directory holding only REPL history isn't a database yet
+	dir, err := Dir("exists")
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.MkdirAll(dir, 0777))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, ".rql_history"), []byte("\\q\n"), 0666))
+	ok, err = Exists("exists")
+	testutil.Ok(t, err)
+	testutil.Assert(t, !ok, "history alone isn't a database")
+
+	_, err = NewFileManager("exists")
+	testutil.Ok(t, err)
+	ok, err = Exists("exists")
+	testutil.Ok(t, err)
+	testutil.Assert(t, ok, "created database exists")
+	testutil.Assert(t, !exists(filepath.Join(dir, "."+HeaderFile+".tmp")), "temporary header is renamed away")
+}
+
+func TestFileManagerIncompatible(t *testing.T) {
+	defer cleanUp("incompatible")
+	fm, err := NewFileManager("incompatible")
+	testutil.Ok(t, err)
+	testutil.Equals(t, DefaultBlockSize, fm.BlockSize)
+
+	err = writeHeader(fm.Dir, &header{version: FormatVersion + 1, blockSize: DefaultBlockSize})
+	testutil.Ok(t, err)
+	_, err = NewFileManager("incompatible")
+	testutil.Equals(t, ErrIncompatibleFormat, errors.Cause(err))
+
+	// a database with files but no header predates headers
+	_, err = fm.Append("users.tbl", make([]byte, DefaultBlockSize))
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.Remove(filepath.Join(fm.Dir, HeaderFile)))
+	_, err = NewFileManager("incompatible")
+	testutil.Equals(t, ErrNotDatabase, errors.Cause(err))
+}
+
+func TestFileManagerBadBlockSize(t *testing.T) {
+	defer cleanUp("badblocksize")
+	_, err := OpenFileManager("badblocksize", Options{BlockSize: 10})
+	testutil.Assert(t, err != nil, "tiny block sizes are refused")
+}
+
+func TestFileManagerTruncateRenameRemove(t *testing.T) {
+	defer cleanUp("ddl")
+	fm, err := NewFileManager("ddl")
+	testutil.Ok(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = fm.Append("users.tbl", make([]byte, DefaultBlockSize))
+		testutil.Ok(t, err)
+	}
+
+	testutil.Ok(t, fm.Truncate("users.tbl", 1))
+	size, err := fm.Size("users.tbl")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, size)
+	testutil.Equals(t, ErrOutOfBounds, fm.Truncate("users.tbl", -1))
+	testutil.Equals(t, ErrOutOfBounds, fm.Truncate("users.tbl", 2))
+	size, err = fm.Size("users.tbl")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, size)
+
+	err = fm.Truncate("missing.tbl", 0)
+	testutil.Assert(t, os.IsNotExist(errors.Cause(err)), "truncating a missing file fails")
+	_, err = os.Stat(filepath.Join(fm.Dir, "missing.tbl"))
+	testutil.Assert(t, os.IsNotExist(err), "truncating doesn't create the file")
+
+	testutil.Ok(t, fm.Rename("users.tbl", "people.tbl"))
+	_, err = os.Stat(filepath.Join(fm.Dir, "users.tbl"))
+	testutil.Assert(t, os.IsNotExist(err), "old name is gone after a rename")
+	size, err = fm.Size("people.tbl")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, size)
+
+	testutil.Ok(t, fm.Remove("people.tbl"))
+	_, err = os.Stat(filepath.Join(fm.Dir, "people.tbl"))
+	testutil.Assert(t, os.IsNotExist(err), "file is gone after being removed")
+
+	err = fm.Remove("people.tbl")
+	testutil.Assert(t, os.IsNotExist(errors.Cause(err)), "removing a missing file fails")
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderFile is the name of the file at the root of every database which
+	// describes how the rest of its files are laid out.
+	HeaderFile = "rql.header"
+	// FormatVersion is the version of the on-disk format written by this
+	// build.  It must be bumped whenever the layout of blocks changes.
+	FormatVersion = 1
+)
+
+// magic identifies a header file as belonging to an RQL database.
+var magic = []byte("RQLDB\x00")
+
+// header is the contents of HeaderFile.  It is encoded as the magic bytes
+// followed by the format version and block size as uint32s.
+type header struct {
+	version   int
+	blockSize int
+}
+
+// readHeader loads the header of the database stored in dir.
+func readHeader(dir string) (*header, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, HeaderFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) != len(magic)+2*IntSize || !bytes.HasPrefix(raw, magic) {
+		return nil, errors.Wrapf(ErrNotDatabase, "%s is not a valid header", filepath.Join(dir, HeaderFile))
+	}
+	raw = raw[len(magic):]
+
+	return &header{
+		version:   int(binary.LittleEndian.Uint32(raw)),
+		blockSize: int(binary.LittleEndian.Uint32(raw[IntSize:])),
+	}, nil
+}
+
+// writeHeader stores h as the header of the database in dir.  The header is
+// written to a temporary file and synced before being renamed into place so a
+// crash never leaves a database with a partial header.
+func writeHeader(dir string, h *header) error {
+	raw := make([]byte, len(magic)+2*IntSize)
+	copy(raw, magic)
+	binary.LittleEndian.PutUint32(raw[len(magic):], uint32(h.version))
+	binary.LittleEndian.PutUint32(raw[len(magic)+IntSize:], uint32(h.blockSize))
+
+	// the temporary file is hidden so it isn't mistaken for data
+	tmp := filepath.Join(dir, "."+HeaderFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing header")
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "syncing header")
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return errors.Wrap(os.Rename(tmp, filepath.Join(dir, HeaderFile)), "installing header")
+}
+
+// validate makes sure a database with this header can be opened by this build.
+func (h *header) validate() error {
+	if h.version != FormatVersion {
+		return errors.Wrapf(
+			ErrIncompatibleFormat,
+			"database uses format version %d but this build of rql supports version %d",
+			h.version, FormatVersion,
+		)
+	}
+	if err := validateBlockSize(h.blockSize); err != nil {
+		return errors.Wrap(ErrIncompatibleFormat, err.Error())
+	}
+	return nil
+}
+
+// validateBlockSize checks that size is within the supported limits.
+func validateBlockSize(size int) error {
+	if size < MinBlockSize || size > MaxBlockSize {
+		return errors.Errorf("block size %d must be between %d and %d", size, MinBlockSize, MaxBlockSize)
+	}
+	return nil
+}
+
+// hasDataFiles reports whether dir holds any files other than hidden ones, such
+// as the REPL history.
+func hasDataFiles(dir string) (bool, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Name(), ".") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
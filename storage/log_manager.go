@@ -1,5 +1,11 @@
 package storage
 
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
 // LogManager is responsible for logging changes in our DBMS so they can be
 // undone.  There is only ever one log file per DB.
 type LogManager struct {
@@ -15,6 +21,10 @@ type LogManager struct {
 	currentPos int
 	// Position of the last record in our log file
 	LastRecordPos int
+	// Torn is set when the last block of the log failed its checksum when the
+	// log was opened.  That happens when a crash interrupts a Flush.  The
+	// block's records are lost and it is started again from scratch.
+	Torn *CorruptPageError
 }
 
 // NewLogManager creates a new log manager.  If a file does not already exist
@@ -38,8 +48,23 @@ func NewLogManager(filename string, fm *FileManager) (*LogManager, error) {
 		}
 	} else {
 		lm.currentBlk = NewBlock(filename, size-1)
-		lm.page.Read(lm.currentBlk)
-		lm.currentPos = lm.getLastRecordPosition() + IntSize
+		err := lm.page.Read(lm.currentBlk)
+		if corrupt, ok := err.(*CorruptPageError); ok {
+			// the log ends with the block before the torn one
+			lm.Torn = corrupt
+			if err := lm.restartBlock(); err != nil {
+				return nil, errors.Wrap(err, "restarting torn log block")
+			}
+			return lm, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lastPos, err := lm.getLastRecordPosition()
+		if err != nil {
+			return nil, err
+		}
+		lm.currentPos = lastPos + IntSize
 	}
 	return lm, nil
 }
@@ -48,43 +73,62 @@ func NewLogManager(filename string, fm *FileManager) (*LogManager, error) {
 // called for two reasons:  1. The page is full and needs to be written so more
 // records can be recorded 2. Other parts of the system need the logs to be
 // recorded before progressing
-func (lm *LogManager) Flush() {
-	lm.page.Write(lm.currentBlk)
+func (lm *LogManager) Flush() error {
+	return lm.page.Write(lm.currentBlk)
 }
 
 // FlushLSN will only flush if the given lsn is bigger than the currently
 // written LSN.  If the lsn is smaller it means that those log records have
 // already been written to disk.
-func (lm *LogManager) FlushLSN(lsn int) {
+func (lm *LogManager) FlushLSN(lsn int) error {
 	if lsn >= lm.currentLSN() {
-		lm.Flush()
+		return lm.Flush()
 	}
+	return nil
 }
 
 // Append determines the size of the log records and appends them in memory.
 // If there is not enough space it will flush the contents to disk and add a new
-// block for the records.
-func (lm *LogManager) Append(lrs []interface{}) int {
+// block for the records.  The LSN of the appended record is returned.  Every
+// value is checked before any are written so a rejected record leaves the log
+// untouched.
+func (lm *LogManager) Append(lrs []interface{}) (int, error) {
 	recordSize := IntSize
 	for _, lr := range lrs {
+		if err := checkValue(lr); err != nil {
+			return 0, err
+		}
 		recordSize += ByteSizeForVal(lr)
 	}
 
+	// A record that doesn't fit in an empty block can never be written.
+	if IntSize+recordSize >= lm.page.Size() {
+		return 0, ErrPageFull
+	}
+
 	// Not enough room, write to disk, and add room.
-	if lm.currentPos+recordSize >= BlockSize {
-		lm.Flush()
-		lm.appendNewBlock()
+	if lm.currentPos+recordSize >= lm.page.Size() {
+		if err := lm.Flush(); err != nil {
+			return 0, errors.Wrap(err, "flushing log")
+		}
+		if err := lm.appendNewBlock(); err != nil {
+			return 0, errors.Wrap(err, "appending log block")
+		}
 	}
 
 	// Add log record to buffer.
 	for _, lr := range lrs {
-		lm.appendValue(lr)
+		if err := lm.appendValue(lr); err != nil {
+			return 0, err
+		}
 	}
 
 	// Offset current values and return LSN.
-	lm.finalizeRecord()
+	if err := lm.finalizeRecord(); err != nil {
+		return 0, err
+	}
 
-	return lm.currentLSN()
+	return lm.currentLSN(), nil
 }
 
 // Iterator returns a LogRecordIterator which can be cycled through.  Log
@@ -97,14 +141,29 @@ func (lm *LogManager) Append(lrs []interface{}) int {
 // The first value would be [3, 4] and second would be [1, 2].  Any in memory
 // records will first be flushed to disk before returning the iterator for
 // accessing records.
-func (lm *LogManager) Iterator() *RecordIterator {
-	lm.Flush()
+func (lm *LogManager) Iterator() (*RecordIterator, error) {
+	if err := lm.Flush(); err != nil {
+		return nil, err
+	}
 	return NewRecordIterator(lm.currentBlk, lm)
 }
 
 // Append Utils
+// restartBlock empties the current block and writes it straight away so it
+// passes its checksum from now on.
+func (lm *LogManager) restartBlock() error {
+	lm.page.reset()
+	if err := lm.setLastRecordPosition(0); err != nil {
+		return err
+	}
+	lm.currentPos = IntSize
+	return lm.page.Write(lm.currentBlk)
+}
+
 func (lm *LogManager) appendNewBlock() error {
-	lm.setLastRecordPosition(0)
+	if err := lm.setLastRecordPosition(0); err != nil {
+		return err
+	}
 
 	blk, err := lm.page.Append(lm.filename)
 	if err != nil {
@@ -116,28 +175,51 @@ func (lm *LogManager) appendNewBlock() error {
 
 	return nil
 }
-func (lm *LogManager) appendValue(lr interface{}) {
+
+// checkValue reports whether lr can be stored in a log record.
+func checkValue(lr interface{}) error {
 	switch lr := lr.(type) {
 	case int:
-		lm.page.SetInt(lm.currentPos, lr)
+		if lr < math.MinInt32 || lr > math.MaxInt32 {
+			return ErrOutOfRange
+		}
 	case string:
-		lm.page.SetString(lm.currentPos, lr)
 	default:
-		panic("Unknown type to append to log record")
+		return errors.Errorf("storage: unknown type %T to append to log record", lr)
+	}
+	return nil
+}
+
+func (lm *LogManager) appendValue(lr interface{}) error {
+	var err error
+	switch lr := lr.(type) {
+	case int:
+		err = lm.page.SetInt(lm.currentPos, lr)
+	case string:
+		err = lm.page.SetString(lm.currentPos, lr)
+	default:
+		err = errors.Errorf("storage: unknown type %T to append to log record", lr)
+	}
+	if err != nil {
+		return err
 	}
 	lm.currentPos += ByteSizeForVal(lr)
+	return nil
 }
 
 // Seek Utils
-func (lm *LogManager) getLastRecordPosition() int {
+func (lm *LogManager) getLastRecordPosition() (int, error) {
 	return lm.page.GetInt(lm.LastRecordPos)
 }
 func (lm *LogManager) setLastRecordPosition(pos int) error {
 	return lm.page.SetInt(lm.LastRecordPos, pos)
 }
 func (lm *LogManager) finalizeRecord() error {
-	lastPos := lm.getLastRecordPosition()
-	err := lm.page.SetInt(lm.currentPos, lastPos)
+	lastPos, err := lm.getLastRecordPosition()
+	if err != nil {
+		return err
+	}
+	err = lm.page.SetInt(lm.currentPos, lastPos)
 	if err != nil {
 		return err
 	}
@@ -168,29 +250,52 @@ func (lm *LogManager) currentLSN() int {
 type LogRecord struct {
 	page *Page
 	pos  int
+	// start and end are the offsets of the records values within the page
+	start int
+	end   int
 }
 
-// NewLogRecord creates a log record at a given pos for a page of memory.
-func NewLogRecord(page *Page, pos int) *LogRecord {
+// NewLogRecord creates a log record for a page of memory whose values start at
+// pos and finish right before end.
+func NewLogRecord(page *Page, pos, end int) *LogRecord {
 	return &LogRecord{
-		page: page,
-		pos:  pos,
+		page:  page,
+		pos:   pos,
+		start: pos,
+		end:   end,
 	}
 }
 
+// Offset returns where the record starts within its block.
+func (lr *LogRecord) Offset() int {
+	return lr.start
+}
+
+// Raw returns a copy of the bytes making up the record's values.  It is
+// useful for debugging when the types of the values are not known.
+func (lr *LogRecord) Raw() []byte {
+	return lr.page.Contents()[lr.start:lr.end]
+}
+
 // NextInt returns the next int in the log record and progresses the pointer.
-func (lr *LogRecord) NextInt() int {
-	nextInt := lr.page.GetInt(lr.pos)
+func (lr *LogRecord) NextInt() (int, error) {
+	nextInt, err := lr.page.GetInt(lr.pos)
+	if err != nil {
+		return 0, err
+	}
 	lr.pos += IntSize
-	return nextInt
+	return nextInt, nil
 }
 
 // NextString returns the next string in the log record and progresses the
 // pointer.
-func (lr *LogRecord) NextString() string {
-	nextStr := lr.page.GetString(lr.pos)
+func (lr *LogRecord) NextString() (string, error) {
+	nextStr, err := lr.page.GetString(lr.pos)
+	if err != nil {
+		return "", err
+	}
 	lr.pos += stringSize(nextStr) + IntSize
-	return nextStr
+	return nextStr, nil
 }
 
 // RecordIterator allows clients to consume a LogRecord in a familiar Next()
@@ -206,16 +311,17 @@ type RecordIterator struct {
 // NewRecordIterator returns a RecordIterator that is ready to start being
 // consumed.  It creates a new page of memory and sets the current records
 // position.
-func NewRecordIterator(blk *Block, lm *LogManager) *RecordIterator {
+func NewRecordIterator(blk *Block, lm *LogManager) (*RecordIterator, error) {
 	ri := &RecordIterator{
 		blk:  blk,
 		page: NewPage(lm.fm), // TODO: I really don't like this...
 		lm:   lm,
 	}
 
-	ri.page.Read(blk)
-	ri.currentRecord = ri.page.GetInt(lm.LastRecordPos)
-	return ri
+	if err := ri.load(blk); err != nil {
+		return nil, err
+	}
+	return ri, nil
 }
 
 // Next can be true in two circumstances:
@@ -228,20 +334,44 @@ func (ri *RecordIterator) Next() bool {
 // Value returns the current LogRecord the iterator is located at.  When getting
 // to the last record in a block (0) the iterator will automatically move on to
 // the next block and continue iterating.
-func (ri *RecordIterator) Value() *LogRecord {
+func (ri *RecordIterator) Value() (*LogRecord, error) {
 	// We got to the end of the current block since the linked chain goes from the
 	// front to end of the file. Load in a new block and continue
 	if ri.currentRecord == 0 {
-		ri.moveToNextBlock()
+		if err := ri.moveToNextBlock(); err != nil {
+			return nil, err
+		}
+	}
+	end := ri.currentRecord
+	next, err := ri.page.GetInt(ri.currentRecord)
+	if err != nil {
+		return nil, err
 	}
-	ri.currentRecord = ri.page.GetInt(ri.currentRecord)
+	ri.currentRecord = next
+
+	lr := NewLogRecord(ri.page, ri.currentRecord+IntSize, end)
+	return lr, nil
+}
 
-	lr := NewLogRecord(ri.page, ri.currentRecord+IntSize)
-	return lr
+// LSN returns the log sequence number of the record last returned by Value.
+func (ri *RecordIterator) LSN() int {
+	return ri.blk.BlockNum
 }
 
-func (ri *RecordIterator) moveToNextBlock() {
-	blk := NewBlock(ri.blk.FileName, ri.blk.BlockNum-1)
-	ri.page.Read(blk)
-	ri.currentRecord = ri.page.GetInt(ri.lm.LastRecordPos)
+func (ri *RecordIterator) moveToNextBlock() error {
+	return ri.load(NewBlock(ri.blk.FileName, ri.blk.BlockNum-1))
+}
+
+// load reads blk and points the iterator at its last record.
+func (ri *RecordIterator) load(blk *Block) error {
+	ri.blk = blk
+	if err := ri.page.Read(blk); err != nil {
+		return err
+	}
+	last, err := ri.page.GetInt(ri.lm.LastRecordPos)
+	if err != nil {
+		return err
+	}
+	ri.currentRecord = last
+	return nil
 }
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spencercdixon/rql/testutil"
@@ -13,11 +15,113 @@ func TestAppendingLogManager(t *testing.T) {
 	lr1 := []interface{}{"hello", "world"}
 	lr2 := []interface{}{1, 2, 3}
 	lr3 := []interface{}{42, "meaning", "of", "life"}
-	lm.Append(lr1)
-	lm.Append(lr2)
-	lm.Append(lr3)
+	_, err := lm.Append(lr1)
+	testutil.Ok(t, err)
+	_, err = lm.Append(lr2)
+	testutil.Ok(t, err)
+	_, err = lm.Append(lr3)
+	testutil.Ok(t, err)
+
+	testutil.Ok(t, lm.Flush())
+}
+
+func TestAppendErrors(t *testing.T) {
+	defer cleanUp("appenderrors")
+	lm := newLogManager(t, "appenderrors")
+
+	_, err := lm.Append([]interface{}{3.14})
+	testutil.Assert(t, err != nil, "unknown types are rejected")
+
+	big := make([]byte, DefaultBlockSize)
+	_, err = lm.Append([]interface{}{string(big)})
+	testutil.Equals(t, ErrPageFull, err)
+}
+
+func TestAppendAfterRejectedRecord(t *testing.T) {
+	defer cleanUp("rejected")
+	lm := newLogManager(t, "rejected")
+
+	_, err := lm.Append([]interface{}{7, 8})
+	testutil.Ok(t, err)
+	_, err = lm.Append([]interface{}{99, 3.14})
+	testutil.Assert(t, err != nil, "unknown types are rejected")
+	_, err = lm.Append([]interface{}{99, 1 << 40})
+	testutil.Equals(t, ErrOutOfRange, err)
+	_, err = lm.Append([]interface{}{1, 2})
+	testutil.Ok(t, err)
+
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	for _, want := range [][]int{{1, 2}, {7, 8}} {
+		testutil.Assert(t, iter.Next(), "more records to read")
+		lr, err := iter.Value()
+		testutil.Ok(t, err)
+		testutil.Equals(t, 2*IntSize, len(lr.Raw()))
+		for _, w := range want {
+			val, err := lr.NextInt()
+			testutil.Ok(t, err)
+			testutil.Equals(t, w, val)
+		}
+	}
+	testutil.Assert(t, !iter.Next(), "rejected records are not in the log")
+}
+
+func TestLogTornLastBlock(t *testing.T) {
+	defer cleanUp("torn")
+	lm := newLogManager(t, "torn")
+
+	// fill the first block so later records land in a second one
+	var lsn int
+	var err error
+	for i := 0; lsn == 0; i++ {
+		lsn, err = lm.Append([]interface{}{i})
+		testutil.Ok(t, err)
+	}
+	testutil.Ok(t, lm.Flush())
+	first := NewPage(lm.fm)
+	testutil.Ok(t, first.Read(NewBlock("torn.log", 0)))
+
+	// a crash part way through writing the second block leaves the front of
+	// it new and the rest old
+	f, err := os.OpenFile(filepath.Join(lm.fm.Dir, "torn.log"), os.O_RDWR, 0)
+	testutil.Ok(t, err)
+	_, err = f.WriteAt(make([]byte, DefaultBlockSize/2), int64(DefaultBlockSize+DefaultBlockSize/2))
+	testutil.Ok(t, err)
+	_, err = f.WriteAt([]byte{1, 2, 3, 4}, int64(DefaultBlockSize+PageHeaderSize+IntSize))
+	testutil.Ok(t, err)
+	testutil.Ok(t, f.Close())
+
+	fm, err := NewFileManager("torn")
+	testutil.Ok(t, err)
+	lm, err = NewLogManager("torn.log", fm)
+	testutil.Ok(t, err)
+	testutil.Assert(t, lm.Torn != nil, "torn block is reported")
+	testutil.Equals(t, 1, lm.Torn.Block.BlockNum)
+
+	// the log carries on in the torn block and the block before is untouched
+	_, err = lm.Append([]interface{}{"after"})
+	testutil.Ok(t, err)
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	iter.Next()
+	lr, err := iter.Value()
+	testutil.Ok(t, err)
+	after, err := lr.NextString()
+	testutil.Ok(t, err)
+	testutil.Equals(t, "after", after)
 
-	lm.Flush()
+	iter.Next()
+	_, err = iter.Value()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, iter.LSN())
+	p := NewPage(fm)
+	testutil.Ok(t, p.Read(NewBlock("torn.log", 0)))
+	testutil.Equals(t, first.Contents(), p.Contents())
+
+	// reopening again finds a healthy log
+	lm, err = NewLogManager("torn.log", fm)
+	testutil.Ok(t, err)
+	testutil.Assert(t, lm.Torn == nil, "restarted block is not torn")
 }
 
 func TestLogIterator(t *testing.T) {
@@ -30,26 +134,79 @@ func TestLogIterator(t *testing.T) {
 	lm.Append(lr2)
 
 	// will Flush
-	iter := lm.Iterator()
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
 
 	// Records proper int records
 	iter.Next()
-	l2 := iter.Value()
-	one := l2.NextInt()
-	forty := l2.NextInt()
+	l2, err := iter.Value()
+	testutil.Ok(t, err)
+	one, err := l2.NextInt()
+	testutil.Ok(t, err)
+	forty, err := l2.NextInt()
+	testutil.Ok(t, err)
 
 	testutil.Equals(t, 1, one)
 	testutil.Equals(t, 40, forty)
 
 	// Records proper string records
 	iter.Next()
-	l1 := iter.Value()
-	hello := l1.NextString()
-	world := l1.NextString()
+	l1, err := iter.Value()
+	testutil.Ok(t, err)
+	hello, err := l1.NextString()
+	testutil.Ok(t, err)
+	world, err := l1.NextString()
+	testutil.Ok(t, err)
 	testutil.Equals(t, hello, "hello")
 	testutil.Equals(t, world, "world")
 }
 
+func TestLogIteratorAcrossBlocks(t *testing.T) {
+	defer cleanUp("multiblock")
+	lm := newLogManager(t, "multiblock")
+
+	// each record takes 12 bytes so this spans several blocks
+	total := 100
+	for i := 0; i < total; i++ {
+		_, err := lm.Append([]interface{}{i, i * 2})
+		testutil.Ok(t, err)
+	}
+
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	lastLSN := iter.LSN()
+	testutil.Assert(t, lastLSN > 0, "records span more than one block")
+
+	count := 0
+	for iter.Next() {
+		lr, err := iter.Value()
+		testutil.Ok(t, err)
+		val, err := lr.NextInt()
+		testutil.Ok(t, err)
+		testutil.Equals(t, total-1-count, val)
+		testutil.Assert(t, iter.LSN() <= lastLSN, "LSNs never increase while iterating")
+		lastLSN = iter.LSN()
+		count++
+	}
+	testutil.Equals(t, total, count)
+	testutil.Equals(t, 0, lastLSN)
+}
+
+func TestLogRecordRaw(t *testing.T) {
+	defer cleanUp("raw")
+	lm := newLogManager(t, "raw")
+	lm.Append([]interface{}{"hi", 7})
+
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	iter.Next()
+	lr, err := iter.Value()
+	testutil.Ok(t, err)
+
+	testutil.Equals(t, IntSize, lr.Offset())
+	testutil.Equals(t, []byte{2, 0, 0, 0, 'h', 'i', 7, 0, 0, 0}, lr.Raw())
+}
+
 func newLogManager(t *testing.T, dbName string) *LogManager {
 	t.Helper()
 	fm, err := NewFileManager(dbName)
@@ -59,3 +216,20 @@ func newLogManager(t *testing.T, dbName string) *LogManager {
 	testutil.Ok(t, err)
 	return lm
 }
+
+func TestLogNegativeInts(t *testing.T) {
+	defer cleanUp("negative")
+	lm := newLogManager(t, "negative")
+
+	_, err := lm.Append([]interface{}{-42})
+	testutil.Ok(t, err)
+
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	iter.Next()
+	lr, err := iter.Value()
+	testutil.Ok(t, err)
+	val, err := lr.NextInt()
+	testutil.Ok(t, err)
+	testutil.Equals(t, -42, val)
+}
@@ -1,11 +1,22 @@
 package storage
 
 import (
-	"bytes"
 	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"time"
 )
 
-// Page is used by the file manager to read and write blocks of bytes.
+// secondsPerDay is used to convert between dates and days since the epoch.
+const secondsPerDay = 24 * 60 * 60
+
+// castagnoli is the CRC32C table used for page checksums.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Page is used by the file manager to read and write blocks of bytes.  The
+// first PageHeaderSize bytes of every block hold a checksum which is stamped on
+// write and verified on read.  Offsets given to a page's getters and setters
+// are relative to the data after the header.
 type Page struct {
 	content []byte
 	fm      *FileManager
@@ -15,7 +26,7 @@ type Page struct {
 // memory.  The memory of a page get's reused to help optimize space
 // constraints.
 func NewPage(fm *FileManager) *Page {
-	content := make([]byte, BlockSize, BlockSize)
+	content := make([]byte, fm.BlockSize, fm.BlockSize)
 
 	return &Page{
 		content: content,
@@ -23,79 +34,312 @@ func NewPage(fm *FileManager) *Page {
 	}
 }
 
-// GetInt gets an int at the given offset of this pages contents.
-func (p *Page) GetInt(offset int) int {
-	end := offset + IntSize
-	return int(binary.LittleEndian.Uint32(p.content[offset:end]))
+// GetInt gets an INT at the given offset of this pages contents.  INTs are
+// signed 32 bit integers.
+func (p *Page) GetInt(offset int) (int, error) {
+	val, err := p.GetInt32(offset)
+	return int(val), err
 }
 
-// SetInt sets an int at the given offset.
+// SetInt sets an INT at the given offset.  ErrOutOfRange is returned if val
+// does not fit in 32 bits rather than silently wrapping.
 func (p *Page) SetInt(offset int, val int) error {
-	if BlockSize-offset < IntSize {
-		return ErrPageFull
+	if val < math.MinInt32 || val > math.MaxInt32 {
+		return ErrOutOfRange
+	}
+	return p.SetInt32(offset, int32(val))
+}
+
+// GetInt32 gets a signed 32 bit integer at the given offset.
+func (p *Page) GetInt32(offset int) (int32, error) {
+	b, err := p.slice(offset, IntSize)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+// SetInt32 sets a signed 32 bit integer at the given offset.
+func (p *Page) SetInt32(offset int, val int32) error {
+	b, err := p.sliceForSet(offset, IntSize)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(b, uint32(val))
+	return nil
+}
+
+// GetInt64 gets a BIGINT at the given offset of this pages contents.  BIGINTs
+// are signed 64 bit integers.
+func (p *Page) GetInt64(offset int) (int64, error) {
+	b, err := p.slice(offset, BigIntSize)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+// SetInt64 sets a BIGINT at the given offset.
+func (p *Page) SetInt64(offset int, val int64) error {
+	b, err := p.sliceForSet(offset, BigIntSize)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(b, uint64(val))
+	return nil
+}
+
+// GetDecimal gets a DECIMAL with the given scale at the given offset of this
+// pages contents.
+func (p *Page) GetDecimal(offset, scale int) (Decimal, error) {
+	unscaled, err := p.GetInt64(offset)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+// SetDecimal sets a DECIMAL at the given offset.  Only the unscaled value is
+// stored so val must already have the scale of its column.
+func (p *Page) SetDecimal(offset int, val Decimal) error {
+	return p.SetInt64(offset, val.Unscaled)
+}
+
+// GetBool gets a BOOLEAN at the given offset of this pages contents.
+func (p *Page) GetBool(offset int) (bool, error) {
+	b, err := p.slice(offset, BoolSize)
+	if err != nil {
+		return false, err
 	}
+	return b[0] != 0, nil
+}
 
-	buf := bytes.NewBuffer(nil)
-	binary.Write(buf, binary.LittleEndian, uint32(val))
-	newBytes := buf.Bytes()
+// SetBool sets a BOOLEAN at the given offset.
+func (p *Page) SetBool(offset int, val bool) error {
+	b, err := p.sliceForSet(offset, BoolSize)
+	if err != nil {
+		return err
+	}
+	b[0] = 0
+	if val {
+		b[0] = 1
+	}
+	return nil
+}
 
-	// insert the new bytes
-	p.content = append(
-		p.content[:offset],
-		append(newBytes, p.content[offset+IntSize:]...)...,
-	)
+// GetFloat64 gets a DOUBLE at the given offset of this pages contents.
+func (p *Page) GetFloat64(offset int) (float64, error) {
+	b, err := p.slice(offset, FloatSize)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
 
+// SetFloat64 sets a DOUBLE at the given offset.
+func (p *Page) SetFloat64(offset int, val float64) error {
+	b, err := p.sliceForSet(offset, FloatSize)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(b, math.Float64bits(val))
 	return nil
 }
 
-// GetString gets a string at the given offset of this pages contents.
-func (p *Page) GetString(offset int) string {
-	intEnd := offset + IntSize
-	numChars := binary.LittleEndian.Uint32(p.content[offset:intEnd])
-	strEnd := numChars + uint32(intEnd)
-	return string(p.content[intEnd:strEnd])
+// GetDate gets a DATE at the given offset of this pages contents.  The date is
+// returned as midnight UTC.
+func (p *Page) GetDate(offset int) (time.Time, error) {
+	days, err := p.GetInt32(offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(days)*secondsPerDay, 0).UTC(), nil
+}
+
+// SetDate sets a DATE at the given offset.  Only the date of val in UTC is
+// kept, the time of day is dropped.
+func (p *Page) SetDate(offset int, val time.Time) error {
+	days := floorDiv(val.Unix(), secondsPerDay)
+	if days < math.MinInt32 || days > math.MaxInt32 {
+		return ErrOutOfRange
+	}
+	return p.SetInt32(offset, int32(days))
+}
+
+// GetTimestamp gets a TIMESTAMP at the given offset of this pages contents.
+func (p *Page) GetTimestamp(offset int) (time.Time, error) {
+	micros, err := p.GetInt64(offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	secs := floorDiv(micros, 1e6)
+	nanos := (micros - secs*1e6) * 1e3
+	return time.Unix(secs, nanos).UTC(), nil
+}
+
+// SetTimestamp sets a TIMESTAMP at the given offset with microsecond
+// precision.  ErrOutOfRange is returned if val is too far from the epoch to
+// count in microseconds.
+func (p *Page) SetTimestamp(offset int, val time.Time) error {
+	secs, micros := val.Unix(), int64(val.Nanosecond()/1e3)
+	if secs < math.MinInt64/1000000 || secs > (math.MaxInt64-micros)/1e6 {
+		return ErrOutOfRange
+	}
+	return p.SetInt64(offset, secs*1e6+micros)
+}
+
+// GetString gets a string at the given offset of this pages contents.  An
+// error is returned if the strings length prefix runs past the end of the
+// page.
+func (p *Page) GetString(offset int) (string, error) {
+	b, err := p.GetBytes(offset)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
 // SetString sets a string at the given offset.
 func (p *Page) SetString(offset int, val string) error {
-	strSizeInt := stringSize(val)
+	return p.SetBytes(offset, []byte(val))
+}
 
-	if BlockSize-offset < IntSize+strSizeInt {
-		return ErrPageFull
+// GetBytes gets a BLOB at the given offset of this pages contents.  BLOBs are
+// stored like strings, with a length prefix.  The returned slice is a copy.
+func (p *Page) GetBytes(offset int) ([]byte, error) {
+	length, err := p.GetInt(offset)
+	if err != nil {
+		return nil, err
+	}
+	b, err := p.slice(offset+IntSize, length)
+	if err != nil {
+		return nil, err
 	}
+	return append([]byte(nil), b...), nil
+}
 
-	buf := bytes.NewBuffer(nil)
-	binary.Write(buf, binary.LittleEndian, uint32(strSizeInt))
-	binary.Write(buf, binary.LittleEndian, []byte(val))
+// SetBytes sets a BLOB at the given offset.
+func (p *Page) SetBytes(offset int, val []byte) error {
+	b, err := p.sliceForSet(offset, IntSize+len(val))
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(b, uint32(len(val)))
+	copy(b[IntSize:], val)
+	return nil
+}
 
-	newBytes := buf.Bytes()
-	// insert the new bytes
-	p.content = append(
-		p.content[:offset],
-		append(newBytes, p.content[offset+IntSize+strSizeInt:]...)...,
-	)
+// IsNull reports whether field is marked as NULL in the null bitmap starting at
+// offset.  A bitmap takes NullBitmapSize bytes and has one bit per field.
+func (p *Page) IsNull(offset, field int) (bool, error) {
+	if field < 0 {
+		return false, ErrOutOfBounds
+	}
+	b, err := p.slice(offset+field/8, 1)
+	if err != nil {
+		return false, err
+	}
+	return b[0]&nullBit(field) != 0, nil
+}
 
+// SetNull marks field as NULL, or not, in the null bitmap starting at offset.
+func (p *Page) SetNull(offset, field int, null bool) error {
+	if field < 0 {
+		return ErrOutOfBounds
+	}
+	b, err := p.sliceForSet(offset+field/8, 1)
+	if err != nil {
+		return err
+	}
+	if null {
+		b[0] |= nullBit(field)
+	} else {
+		b[0] &^= nullBit(field)
+	}
 	return nil
 }
 
+func nullBit(field int) byte {
+	return 1 << uint(field%8)
+}
+
+// slice returns the size bytes of data starting at offset.  The returned slice
+// shares memory with the page so writing to it updates the page in place.
+func (p *Page) slice(offset, size int) ([]byte, error) {
+	if offset < 0 || size < 0 || offset > p.Size()-size {
+		return nil, ErrOutOfBounds
+	}
+	start := PageHeaderSize + offset
+	return p.content[start : start+size], nil
+}
+
+// sliceForSet is like slice but reports a lack of room as ErrPageFull.
+func (p *Page) sliceForSet(offset, size int) ([]byte, error) {
+	if offset >= 0 && p.Size()-offset < size {
+		return nil, ErrPageFull
+	}
+	return p.slice(offset, size)
+}
+
+// Contents returns a copy of the data held by this page, not including the
+// page header.
+func (p *Page) Contents() []byte {
+	content := make([]byte, p.Size())
+	copy(content, p.content[PageHeaderSize:])
+	return content
+}
+
+// Size returns the number of bytes available for data in this page once the
+// header has been reserved.
+func (p *Page) Size() int {
+	return len(p.content) - PageHeaderSize
+}
+
 // Read resets our byte slice and then reads the correct block offset of a file
-// into the byte slice to be used for setting/getting and writing.
-func (p *Page) Read(blk *Block) {
+// into the byte slice to be used for setting/getting and writing.  A
+// *CorruptPageError is returned if the block does not match its checksum.  The
+// contents are still loaded so they can be inspected.
+func (p *Page) Read(blk *Block) error {
 	p.reset()
-	p.fm.Read(blk, p.content)
+	if err := p.fm.Read(blk, p.content); err != nil {
+		return err
+	}
+	return p.verify(blk)
 }
 
 // Write persists the pages contents to disk in a synchronous manner.
-func (p *Page) Write(blk *Block) {
-	p.fm.Write(blk, p.content)
+func (p *Page) Write(blk *Block) error {
+	p.stamp()
+	return p.fm.Write(blk, p.content)
 }
 
 // Append increments to the next available block and appends the bytes in this
 // pages contents.
 func (p *Page) Append(filename string) (*Block, error) {
+	p.stamp()
 	return p.fm.Append(filename, p.content)
 }
 
+// stamp stores the checksum of the pages data in its header.
+func (p *Page) stamp() {
+	binary.LittleEndian.PutUint32(p.content, p.checksum())
+}
+
+// verify compares the checksum in the header with the pages data.  Blocks that
+// are entirely zero have never been written and are always valid.
+func (p *Page) verify(blk *Block) error {
+	stored := binary.LittleEndian.Uint32(p.content)
+	computed := p.checksum()
+	if stored == computed || (stored == 0 && isZero(p.content)) {
+		return nil
+	}
+	return &CorruptPageError{Block: blk, Stored: stored, Computed: computed}
+}
+
+func (p *Page) checksum() uint32 {
+	return crc32.Checksum(p.content[PageHeaderSize:], castagnoli)
+}
+
 // reset wipes the contents clean but preserves the underlying storage for use
 // by future writes.
 func (p *Page) reset() {
@@ -103,3 +347,22 @@ func (p *Page) reset() {
 		p.content[i] = 0
 	}
 }
+
+// floorDiv divides rounding towards negative infinity so dates and times
+// before the epoch land in the right day or second.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
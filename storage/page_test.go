@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/spencercdixon/rql/testutil"
 )
@@ -10,8 +12,9 @@ func TestPageInt(t *testing.T) {
 	defer cleanUp("example")
 	p := newPage(t, "example")
 
-	p.SetInt(0, 42)
-	myInt := p.GetInt(0)
+	testutil.Ok(t, p.SetInt(0, 42))
+	myInt, err := p.GetInt(0)
+	testutil.Ok(t, err)
 	testutil.Equals(t, 42, myInt)
 }
 
@@ -19,8 +22,9 @@ func TestPageString(t *testing.T) {
 	defer cleanUp("example")
 	p := newPage(t, "example")
 
-	p.SetString(0, "hello")
-	str := p.GetString(0)
+	testutil.Ok(t, p.SetString(0, "hello"))
+	str, err := p.GetString(0)
+	testutil.Ok(t, err)
 	testutil.Equals(t, "hello", str)
 }
 
@@ -28,15 +32,19 @@ func TestPageCombined(t *testing.T) {
 	defer cleanUp("example")
 	p := newPage(t, "example")
 
-	p.SetInt(0, 20)
-	p.SetInt(10, 42)
-	p.SetString(15, "hello")
-	p.SetString(80, "world")
+	testutil.Ok(t, p.SetInt(0, 20))
+	testutil.Ok(t, p.SetInt(10, 42))
+	testutil.Ok(t, p.SetString(15, "hello"))
+	testutil.Ok(t, p.SetString(80, "world"))
 
-	twenty := p.GetInt(0)
-	fourtytwo := p.GetInt(10)
-	hello := p.GetString(15)
-	world := p.GetString(80)
+	twenty, err := p.GetInt(0)
+	testutil.Ok(t, err)
+	fourtytwo, err := p.GetInt(10)
+	testutil.Ok(t, err)
+	hello, err := p.GetString(15)
+	testutil.Ok(t, err)
+	world, err := p.GetString(80)
+	testutil.Ok(t, err)
 
 	testutil.Equals(t, 20, twenty)
 	testutil.Equals(t, 42, fourtytwo)
@@ -55,6 +63,65 @@ func TestSetErrors(t *testing.T) {
 	// not enough room for string
 	err = p.SetString(390, "this is one long string")
 	testutil.Equals(t, err, ErrPageFull)
+
+	// offsets before the start of the page
+	err = p.SetInt(-1, 542)
+	testutil.Equals(t, err, ErrOutOfBounds)
+	err = p.SetString(-4, "hello")
+	testutil.Equals(t, err, ErrOutOfBounds)
+}
+
+func TestGetErrors(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	_, err := p.GetInt(p.Size() - 2)
+	testutil.Equals(t, ErrOutOfBounds, err)
+	_, err = p.GetInt(-1)
+	testutil.Equals(t, ErrOutOfBounds, err)
+
+	// a length prefix that runs past the end of the page
+	testutil.Ok(t, p.SetInt(0, p.Size()))
+	_, err = p.GetString(0)
+	testutil.Equals(t, ErrOutOfBounds, err)
+}
+
+func TestPageSizeIsFixed(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	testutil.Ok(t, p.SetString(0, "hello"))
+	testutil.Ok(t, p.SetString(0, "hi"))
+	testutil.Ok(t, p.SetInt(p.Size()-IntSize, 1))
+	testutil.Equals(t, DefaultBlockSize, len(p.content))
+	testutil.Equals(t, DefaultBlockSize, cap(p.content))
+}
+
+func TestPageChecksum(t *testing.T) {
+	defer cleanUp("checksum")
+	p := newPage(t, "checksum")
+	blk := NewBlock("users.tbl", 0)
+
+	// never written blocks are empty rather than corrupt
+	testutil.Ok(t, p.Read(blk))
+
+	testutil.Ok(t, p.SetString(0, "hello"))
+	testutil.Ok(t, p.Write(blk))
+	testutil.Ok(t, p.Read(blk))
+	hello, err := p.GetString(0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "hello", hello)
+
+	// flip a byte behind the page's back
+	file, err := p.fm.getFile(blk.FileName)
+	testutil.Ok(t, err)
+	_, err = file.WriteAt([]byte{'j'}, PageHeaderSize+IntSize)
+	testutil.Ok(t, err)
+
+	err = p.Read(blk)
+	corrupt, ok := err.(*CorruptPageError)
+	testutil.Assert(t, ok, "expected a corrupt page error, got %v", err)
+	testutil.Assert(t, corrupt.Block.Equals(blk), "error names the block")
 }
 
 func newPage(t *testing.T, dbName string) *Page {
@@ -63,3 +130,133 @@ func newPage(t *testing.T, dbName string) *Page {
 	testutil.Ok(t, err)
 	return NewPage(fm)
 }
+
+func TestPageColumnTypes(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	opened := time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC)
+	seen := time.Date(1969, 7, 20, 20, 17, 40, 123456000, time.UTC)
+
+	testutil.Ok(t, p.SetInt64(0, -1<<40))
+	testutil.Ok(t, p.SetBool(8, true))
+	testutil.Ok(t, p.SetFloat64(9, -3.14))
+	testutil.Ok(t, p.SetDate(17, seen))
+	testutil.Ok(t, p.SetTimestamp(21, seen))
+	testutil.Ok(t, p.SetBytes(29, []byte{0xde, 0xad}))
+	testutil.Ok(t, p.SetDecimal(35, Decimal{-1250, 2}))
+
+	big, err := p.GetInt64(0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(-1<<40), big)
+
+	active, err := p.GetBool(8)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, active)
+
+	rate, err := p.GetFloat64(9)
+	testutil.Ok(t, err)
+	testutil.Equals(t, -3.14, rate)
+
+	date, err := p.GetDate(17)
+	testutil.Ok(t, err)
+	testutil.Equals(t, opened, date)
+
+	ts, err := p.GetTimestamp(21)
+	testutil.Ok(t, err)
+	testutil.Equals(t, seen, ts)
+
+	blob, err := p.GetBytes(29)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte{0xde, 0xad}, blob)
+
+	price, err := p.GetDecimal(35, 2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, Decimal{-1250, 2}, price)
+}
+
+func TestPageColumnTypeErrors(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	testutil.Equals(t, ErrPageFull, p.SetInt64(p.Size()-4, 1))
+	testutil.Equals(t, ErrPageFull, p.SetFloat64(p.Size()-4, 1))
+	testutil.Equals(t, ErrPageFull, p.SetBool(p.Size(), true))
+	testutil.Equals(t, ErrPageFull, p.SetBytes(p.Size()-5, []byte{1, 2}))
+
+	farFuture := time.Date(9999999, 1, 1, 0, 0, 0, 0, time.UTC)
+	testutil.Equals(t, ErrOutOfRange, p.SetDate(0, farFuture))
+	testutil.Equals(t, ErrOutOfRange, p.SetTimestamp(0, time.Date(300000, 1, 1, 0, 0, 0, 0, time.UTC)))
+	testutil.Equals(t, ErrOutOfRange, p.SetTimestamp(0, time.Date(-300000, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	// the largest timestamp that fits still round trips
+	latest := time.Unix(math.MaxInt64/1000000, math.MaxInt64%1000000*1000).UTC()
+	testutil.Ok(t, p.SetTimestamp(0, latest))
+	ts, err := p.GetTimestamp(0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, latest, ts)
+	testutil.Equals(t, ErrOutOfRange, p.SetTimestamp(0, latest.Add(time.Microsecond)))
+
+	_, err = p.GetTimestamp(p.Size() - 1)
+	testutil.Equals(t, ErrOutOfBounds, err)
+}
+
+func TestPageSignedInts(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	testutil.Ok(t, p.SetInt(0, -1))
+	neg, err := p.GetInt(0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, -1, neg)
+
+	testutil.Ok(t, p.SetInt32(4, math.MinInt32))
+	min, err := p.GetInt32(4)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int32(math.MinInt32), min)
+
+	testutil.Ok(t, p.SetInt64(8, math.MinInt64))
+	min64, err := p.GetInt64(8)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(math.MinInt64), min64)
+
+	// values that don't fit in an INT are refused rather than wrapped
+	testutil.Equals(t, ErrOutOfRange, p.SetInt(0, math.MaxInt32+1))
+	testutil.Equals(t, ErrOutOfRange, p.SetInt(0, math.MinInt32-1))
+	still, err := p.GetInt(0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, -1, still)
+
+	// a negative length prefix is not a valid string
+	_, err = p.GetString(0)
+	testutil.Equals(t, ErrOutOfBounds, err)
+}
+
+func TestPageNullBitmap(t *testing.T) {
+	defer cleanUp("example")
+	p := newPage(t, "example")
+
+	// a record with ten fields needs two bytes of bitmap
+	testutil.Equals(t, 2, NullBitmapSize(10))
+	testutil.Ok(t, p.SetInt(12, -1))
+
+	testutil.Ok(t, p.SetNull(10, 0, true))
+	testutil.Ok(t, p.SetNull(10, 9, true))
+	testutil.Ok(t, p.SetNull(10, 9, false))
+	testutil.Ok(t, p.SetNull(10, 8, true))
+
+	for field, exp := range []bool{true, false, false, false, false, false, false, false, true, false} {
+		null, err := p.IsNull(10, field)
+		testutil.Ok(t, err)
+		testutil.Equals(t, exp, null)
+	}
+
+	// the bitmap doesn't spill into neighbouring values
+	neighbour, err := p.GetInt(12)
+	testutil.Ok(t, err)
+	testutil.Equals(t, -1, neighbour)
+
+	_, err = p.IsNull(0, -1)
+	testutil.Equals(t, ErrOutOfBounds, err)
+	testutil.Equals(t, ErrPageFull, p.SetNull(p.Size()-1, 8, true))
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// SequenceExt is the extension of the file a sequence is stored in.
+const SequenceExt = ".seq"
+
+// Sequence hands out increasing INTs for SERIAL columns and CREATE SEQUENCE.
+// The last value handed out is stored in the first block of the sequence's
+// file and is read again on every call to Next, so any number of handles on
+// the same sequence share it.
+//
+// Each value is flushed to the log and then written to the block before it is
+// returned.  A crash part way through writing the block leaves it failing its
+// checksum, and the sequence then carries on from the highest value in the
+// log so no value is handed out twice.
+type Sequence struct {
+	name string
+	fm   *FileManager
+	lm   *LogManager
+	page *Page
+	blk  *Block
+	// current is the value last returned by Next on this Sequence
+	current int
+	called  bool
+}
+
+// NewSequence opens the sequence called name.  The sequence's file is created
+// the first time Next is called, and the first value handed out is 1.
+func NewSequence(name string, fm *FileManager, lm *LogManager) *Sequence {
+	return &Sequence{
+		name: name,
+		fm:   fm,
+		lm:   lm,
+		page: NewPage(fm),
+		blk:  NewBlock(name+SequenceExt, 0),
+	}
+}
+
+// Next advances the sequence and returns its new value, like nextval.
+// ErrOutOfRange is returned once the sequence has reached the largest INT.
+func (s *Sequence) Next() (int, error) {
+	s.fm.sequenceMu.Lock()
+	defer s.fm.sequenceMu.Unlock()
+
+	// another handle may have moved the sequence on since we last looked
+	last, err := s.last()
+	if err != nil {
+		return 0, err
+	}
+	if last == math.MaxInt32 {
+		return 0, ErrOutOfRange
+	}
+	val := last + 1
+
+	lsn, err := s.lm.Append([]interface{}{"nextval", s.name, val})
+	if err != nil {
+		return 0, errors.Wrap(err, "logging sequence")
+	}
+	if err := s.lm.FlushLSN(lsn); err != nil {
+		return 0, errors.Wrap(err, "flushing log")
+	}
+
+	if err := s.page.SetInt(0, val); err != nil {
+		return 0, err
+	}
+	if err := s.page.Write(s.blk); err != nil {
+		return 0, err
+	}
+
+	s.current = val
+	s.called = true
+	return val, nil
+}
+
+// last reads the value last handed out from the sequence's block, falling
+// back to the log when the block is torn.
+func (s *Sequence) last() (int, error) {
+	err := s.page.Read(s.blk)
+	if _, ok := err.(*CorruptPageError); ok {
+		return s.recover(err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return s.page.GetInt(0)
+}
+
+// recover finds the highest value logged for the sequence.  corrupt is
+// returned if the log holds none, since starting again from 1 would hand out
+// values twice.
+func (s *Sequence) recover(corrupt error) (int, error) {
+	iter, err := s.lm.Iterator()
+	if err != nil {
+		return 0, err
+	}
+
+	last, found := 0, false
+	for iter.Next() {
+		lr, err := iter.Value()
+		if err != nil {
+			return 0, err
+		}
+		// other kinds of records don't start with two strings
+		if op, err := lr.NextString(); err != nil || op != "nextval" {
+			continue
+		}
+		if name, err := lr.NextString(); err != nil || name != s.name {
+			continue
+		}
+		val, err := lr.NextInt()
+		if err != nil {
+			return 0, err
+		}
+		if !found || val > last {
+			last, found = val, true
+		}
+	}
+	if !found {
+		return 0, corrupt
+	}
+
+	// the torn contents are replaced when the next value is written
+	s.page.reset()
+	return last, nil
+}
+
+// Current returns the value last returned by Next on this Sequence, like
+// currval.  ErrNoCurrentValue is returned if Next has not been called yet.
+func (s *Sequence) Current() (int, error) {
+	if !s.called {
+		return 0, ErrNoCurrentValue
+	}
+	return s.current, nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spencercdixon/rql/testutil"
+)
+
+func TestSequence(t *testing.T) {
+	defer cleanUp("sequence")
+	lm := newLogManager(t, "sequence")
+
+	seq := NewSequence("users_id", lm.fm, lm)
+	_, err := seq.Current()
+	testutil.Equals(t, ErrNoCurrentValue, err)
+
+	for want := 1; want <= 3; want++ {
+		val, err := seq.Next()
+		testutil.Ok(t, err)
+		testutil.Equals(t, want, val)
+	}
+	cur, err := seq.Current()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, cur)
+
+	// reopening carries on where the sequence left off
+	seq = NewSequence("users_id", lm.fm, lm)
+	val, err := seq.Next()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, val)
+
+	// every value is logged before it is handed out
+	iter, err := lm.Iterator()
+	testutil.Ok(t, err)
+	iter.Next()
+	lr, err := iter.Value()
+	testutil.Ok(t, err)
+	op, err := lr.NextString()
+	testutil.Ok(t, err)
+	name, err := lr.NextString()
+	testutil.Ok(t, err)
+	logged, err := lr.NextInt()
+	testutil.Ok(t, err)
+	testutil.Equals(t, "nextval", op)
+	testutil.Equals(t, "users_id", name)
+	testutil.Equals(t, 4, logged)
+}
+
+func TestSequenceSharedHandles(t *testing.T) {
+	defer cleanUp("sharedseq")
+	lm := newLogManager(t, "sharedseq")
+
+	a := NewSequence("ids", lm.fm, lm)
+	b := NewSequence("ids", lm.fm, lm)
+
+	for _, want := range []struct {
+		seq *Sequence
+		val int
+	}{{a, 1}, {b, 2}, {b, 3}, {a, 4}} {
+		val, err := want.seq.Next()
+		testutil.Ok(t, err)
+		testutil.Equals(t, want.val, val)
+	}
+
+	// currval is per handle
+	cur, err := a.Current()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, cur)
+	cur, err = b.Current()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, cur)
+}
+
+func TestSequenceConcurrent(t *testing.T) {
+	defer cleanUp("concurrentseq")
+	lm := newLogManager(t, "concurrentseq")
+
+	workers, each := 4, 25
+	vals := make(chan int, workers*each)
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			seq := NewSequence("ids", lm.fm, lm)
+			for i := 0; i < each; i++ {
+				val, err := seq.Next()
+				if err != nil {
+					errs <- err
+					return
+				}
+				vals <- val
+			}
+			errs <- nil
+		}()
+	}
+	for w := 0; w < workers; w++ {
+		testutil.Ok(t, <-errs)
+	}
+	close(vals)
+
+	seen := map[int]bool{}
+	for val := range vals {
+		testutil.Assert(t, !seen[val], "value %d handed out twice", val)
+		seen[val] = true
+	}
+	testutil.Equals(t, workers*each, len(seen))
+}
+
+func TestSequenceTorn(t *testing.T) {
+	defer cleanUp("tornseq")
+	lm := newLogManager(t, "tornseq")
+
+	// values from another sequence in the log are ignored
+	_, err := NewSequence("other", lm.fm, lm).Next()
+	testutil.Ok(t, err)
+	seq := NewSequence("ids", lm.fm, lm)
+	for i := 0; i < 3; i++ {
+		_, err := seq.Next()
+		testutil.Ok(t, err)
+	}
+
+	tear := func(name string) {
+		f, err := os.OpenFile(filepath.Join(lm.fm.Dir, name+SequenceExt), os.O_RDWR, 0)
+		testutil.Ok(t, err)
+		_, err = f.WriteAt([]byte{0xff}, PageHeaderSize)
+		testutil.Ok(t, err)
+		testutil.Ok(t, f.Close())
+	}
+
+	// a torn block carries on from the highest value in the log
+	tear("ids")
+	val, err := NewSequence("ids", lm.fm, lm).Next()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, val)
+	val, err = NewSequence("ids", lm.fm, lm).Next()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 5, val)
+
+	// without anything in the log the damage is reported
+	other := newLogManager(t, "tornseq2")
+	defer cleanUp("tornseq2")
+	tear("ids")
+	_, err = NewSequence("ids", lm.fm, other).Next()
+	_, ok := err.(*CorruptPageError)
+	testutil.Assert(t, ok, "expected a *CorruptPageError, got %v", err)
+}
+
+func TestSequenceExhausted(t *testing.T) {
+	defer cleanUp("exhausted")
+	lm := newLogManager(t, "exhausted")
+
+	p := NewPage(lm.fm)
+	testutil.Ok(t, p.SetInt(0, math.MaxInt32))
+	testutil.Ok(t, p.Write(NewBlock("ids"+SequenceExt, 0)))
+
+	_, err := NewSequence("ids", lm.fm, lm).Next()
+	testutil.Equals(t, ErrOutOfRange, err)
+}
@@ -1,6 +1,9 @@
 package storage
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 // Exists returns a bool of wether or not a path exists
 func exists(path string) bool {
@@ -19,15 +22,31 @@ func stringSize(s string) int {
 	return len(s)
 }
 
+// NullBitmapSize returns the number of bytes needed for a null bitmap with one
+// bit for each of fields.
+func NullBitmapSize(fields int) int {
+	return (fields + 7) / 8
+}
+
 // ByteSizeForVal determines how many bytes the given val will take up when
-// saved in binary format in the RQL DBMS. Only int/string are currently
-// supported but in the future other types may be added.
+// saved in binary format in the RQL DBMS.  A time.Time is sized as a
+// TIMESTAMP, use DateSize for dates.  Unsupported types take up 0 bytes.
 func ByteSizeForVal(val interface{}) int {
 	switch val := val.(type) {
-	case int:
+	case int, int32:
 		return IntSize
+	case int64, Decimal:
+		return BigIntSize
+	case bool:
+		return BoolSize
+	case float64:
+		return FloatSize
+	case time.Time:
+		return TimestampSize
 	case string:
 		return stringSize(val) + IntSize
+	case []byte:
+		return len(val) + IntSize
 	default:
 		return 0
 	}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spencercdixon/rql/testutil"
 )
@@ -14,3 +15,21 @@ func TestByteSize(t *testing.T) {
 		"extra 4 bytes for strings plus 1 byte per rune",
 	)
 }
+
+func TestByteSizeColumnTypes(t *testing.T) {
+	testutil.Equals(t, 4, ByteSizeForVal(int32(1)))
+	testutil.Equals(t, 8, ByteSizeForVal(int64(1)))
+	testutil.Equals(t, 8, ByteSizeForVal(Decimal{1250, 2}))
+	testutil.Equals(t, 1, ByteSizeForVal(true))
+	testutil.Equals(t, 8, ByteSizeForVal(3.14))
+	testutil.Equals(t, 8, ByteSizeForVal(time.Now()))
+	testutil.Equals(t, 6, ByteSizeForVal([]byte{1, 2}))
+	testutil.Equals(t, 0, ByteSizeForVal(struct{}{}))
+}
+
+func TestNullBitmapSize(t *testing.T) {
+	testutil.Equals(t, 0, NullBitmapSize(0))
+	testutil.Equals(t, 1, NullBitmapSize(1))
+	testutil.Equals(t, 1, NullBitmapSize(8))
+	testutil.Equals(t, 2, NullBitmapSize(9))
+}
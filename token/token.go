@@ -1,7 +1,10 @@
 // Package token contains all of the lexical RQL tokens used by the lexer.
 package token
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Type is a human readable form of our Tokens.  It is less efficient than
 // using an iota with ints but makes building the toy DB easier to work with and
@@ -24,6 +27,11 @@ const (
 	IDENT      Type = "IDENT"
 	STRING_TOK      = "STRING_TOK"
 	INT_TOK         = "INT_TOK"
+	FLOAT_TOK       = "FLOAT_TOK"
+	BLOB_TOK        = "BLOB_TOK"
+
+	// Placeholders
+	PARAM Type = "PARAM"
 
 	// Operators
 	ASSIGN Type = "="
@@ -37,41 +45,120 @@ const (
 	RPAREN         = ")"
 
 	// Keywords
-	AND    Type = "AND"
-	CREATE      = "CREATE"
-	DELETE      = "DELETE"
-	FROM        = "FROM"
-	INDEX       = "INDEX"
-	INSERT      = "INSERT"
-	INTO        = "INTO"
-	ON          = "ON"
-	SELECT      = "SELECT"
-	TABLE       = "TABLE"
-	UPDATE      = "UPDATE"
-	VALUES      = "VALUES"
-	WHERE       = "WHERE"
+	ACTION     Type = "ACTION"
+	ADD             = "ADD"
+	ALTER           = "ALTER"
+	AND             = "AND"
+	CASCADE         = "CASCADE"
+	CHECK           = "CHECK"
+	COLUMN          = "COLUMN"
+	CREATE          = "CREATE"
+	DEFAULT         = "DEFAULT"
+	DELETE          = "DELETE"
+	DROP            = "DROP"
+	EXISTS          = "EXISTS"
+	FALSE           = "FALSE"
+	FOREIGN         = "FOREIGN"
+	FROM            = "FROM"
+	IF              = "IF"
+	INDEX           = "INDEX"
+	INSERT          = "INSERT"
+	INTO            = "INTO"
+	IS              = "IS"
+	KEY             = "KEY"
+	NO              = "NO"
+	NOT             = "NOT"
+	NULL            = "NULL"
+	ON              = "ON"
+	PRIMARY         = "PRIMARY"
+	REFERENCES      = "REFERENCES"
+	RENAME          = "RENAME"
+	RESTRICT        = "RESTRICT"
+	RETURNING       = "RETURNING"
+	SELECT          = "SELECT"
+	SEQUENCE        = "SEQUENCE"
+	SET             = "SET"
+	TABLE           = "TABLE"
+	TO              = "TO"
+	TRUE            = "TRUE"
+	TRUNCATE        = "TRUNCATE"
+	UNIQUE          = "UNIQUE"
+	UPDATE          = "UPDATE"
+	VALUES          = "VALUES"
+	WHERE           = "WHERE"
 
 	// Column Types
-	VARCHAR = "VARCHAR"
-	INT     = "INT"
+	VARCHAR   = "VARCHAR"
+	INT       = "INT"
+	BIGINT    = "BIGINT"
+	BOOLEAN   = "BOOLEAN"
+	FLOAT     = "FLOAT"
+	DOUBLE    = "DOUBLE"
+	DECIMAL   = "DECIMAL"
+	DATE      = "DATE"
+	TIMESTAMP = "TIMESTAMP"
+	BLOB      = "BLOB"
+	SERIAL    = "SERIAL"
+
+	// Column Attributes
+	AUTO_INCREMENT = "AUTO_INCREMENT"
 )
 
 var keywords = map[string]Type{
-	"and":     AND,
-	"create":  CREATE,
-	"delete":  DELETE,
-	"from":    FROM,
-	"index":   INDEX,
-	"insert":  INSERT,
-	"int":     INT,
-	"into":    INTO,
-	"on":      ON,
-	"select":  SELECT,
-	"table":   TABLE,
-	"update":  UPDATE,
-	"values":  VALUES,
-	"varchar": VARCHAR,
-	"where":   WHERE,
+	"action":         ACTION,
+	"add":            ADD,
+	"alter":          ALTER,
+	"and":            AND,
+	"auto_increment": AUTO_INCREMENT,
+	"bigint":         BIGINT,
+	"blob":           BLOB,
+	"bool":           BOOLEAN,
+	"boolean":        BOOLEAN,
+	"cascade":        CASCADE,
+	"check":          CHECK,
+	"column":         COLUMN,
+	"create":         CREATE,
+	"date":           DATE,
+	"decimal":        DECIMAL,
+	"default":        DEFAULT,
+	"delete":         DELETE,
+	"double":         DOUBLE,
+	"drop":           DROP,
+	"exists":         EXISTS,
+	"false":          FALSE,
+	"float":          FLOAT,
+	"foreign":        FOREIGN,
+	"from":           FROM,
+	"if":             IF,
+	"index":          INDEX,
+	"insert":         INSERT,
+	"int":            INT,
+	"into":           INTO,
+	"is":             IS,
+	"key":            KEY,
+	"no":             NO,
+	"not":            NOT,
+	"null":           NULL,
+	"on":             ON,
+	"primary":        PRIMARY,
+	"references":     REFERENCES,
+	"rename":         RENAME,
+	"restrict":       RESTRICT,
+	"returning":      RETURNING,
+	"select":         SELECT,
+	"sequence":       SEQUENCE,
+	"serial":         SERIAL,
+	"set":            SET,
+	"table":          TABLE,
+	"timestamp":      TIMESTAMP,
+	"to":             TO,
+	"true":           TRUE,
+	"truncate":       TRUNCATE,
+	"unique":         UNIQUE,
+	"update":         UPDATE,
+	"values":         VALUES,
+	"varchar":        VARCHAR,
+	"where":          WHERE,
 }
 
 func LookupIdent(ident string) Type {
@@ -82,3 +169,13 @@ func LookupIdent(ident string) Type {
 	}
 	return IDENT
 }
+
+// Keywords returns every keyword in lower case and sorted alphabetically.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}